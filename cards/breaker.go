@@ -0,0 +1,313 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// ErrBreakerOpen is returned while the circuit breaker is open and no fallback is configured
+var ErrBreakerOpen = errors.New("card source unavailable: circuit breaker is open")
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed passes all requests to the primary source
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails fast or serves requests from the fallback source
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe request through to the primary source
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker is a circuit breaker in front of a card Source. It opens after a
+// number of consecutive failures of the primary source, like fabdb API
+// errors or timeouts. While open, requests fail fast or are served by a fallback
+// source. After a cooldown a single probe request is passed through, closing
+// the breaker again if it succeeds. Set and deck lookups share the state of
+// the breaker, they fail fast if the fallback doesn't provide them.
+type Breaker struct {
+	primary   Source
+	fallback  Source
+	threshold int
+	cooldown  time.Duration
+	timeout   time.Duration
+	onChange  func(from, to BreakerState)
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+}
+
+// BreakerOption passed to NewBreaker to change the default instance.
+type BreakerOption func(b *Breaker)
+
+// NewBreaker returns a circuit breaker in front of the primary Source
+func NewBreaker(primary Source, opts ...BreakerOption) *Breaker {
+	b := &Breaker{
+		primary:   primary,
+		threshold: defaultBreakerThreshold,
+		cooldown:  defaultBreakerCooldown,
+		onChange:  func(from, to BreakerState) {},
+		now:       time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// WithFallback sets the source serving requests while the breaker is open
+func WithFallback(s Source) BreakerOption {
+	return func(b *Breaker) {
+		b.fallback = s
+	}
+}
+
+// WithFailureThreshold sets the number of consecutive failures opening the breaker
+func WithFailureThreshold(n int) BreakerOption {
+	return func(b *Breaker) {
+		if n > 0 {
+			b.threshold = n
+		}
+	}
+}
+
+// WithCooldown sets how long the breaker stays open before probing the primary source
+func WithCooldown(d time.Duration) BreakerOption {
+	return func(b *Breaker) {
+		b.cooldown = d
+	}
+}
+
+// WithTimeout limits the duration of each request to the primary source
+func WithTimeout(d time.Duration) BreakerOption {
+	return func(b *Breaker) {
+		b.timeout = d
+	}
+}
+
+// WithStateChangeFunc sets a function called whenever the breaker changes its state
+func WithStateChangeFunc(fn func(from, to BreakerState)) BreakerOption {
+	return func(b *Breaker) {
+		b.onChange = fn
+	}
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) ListCards(ctx context.Context, query string) ([]fabdb.Card, error) {
+	var res []fabdb.Card
+	err := b.call(ctx, func(ctx context.Context, s Source) (err error) {
+		res, err = s.ListCards(ctx, query)
+		return err
+	})
+	return res, err
+}
+
+func (b *Breaker) SearchCards(ctx context.Context, opts fabdb.SearchOptions) ([]fabdb.Card, error) {
+	var res []fabdb.Card
+	err := b.call(ctx, func(ctx context.Context, s Source) (err error) {
+		res, err = s.SearchCards(ctx, opts)
+		return err
+	})
+	return res, err
+}
+
+func (b *Breaker) GetCard(ctx context.Context, identifier string) (fabdb.Card, error) {
+	var res fabdb.Card
+	err := b.call(ctx, func(ctx context.Context, s Source) (err error) {
+		res, err = s.GetCard(ctx, identifier)
+		return err
+	})
+	return res, err
+}
+
+func (b *Breaker) ListSets(ctx context.Context) ([]fabdb.Set, error) {
+	var res []fabdb.Set
+	err := b.call(ctx, func(ctx context.Context, s Source) (err error) {
+		sets, ok := s.(SetSource)
+		if !ok {
+			return unsupported(OpListSets)
+		}
+		res, err = sets.ListSets(ctx)
+		return err
+	})
+	return res, err
+}
+
+func (b *Breaker) GetSet(ctx context.Context, code string) (fabdb.Set, error) {
+	var res fabdb.Set
+	err := b.call(ctx, func(ctx context.Context, s Source) (err error) {
+		sets, ok := s.(SetSource)
+		if !ok {
+			return unsupported(OpGetSet)
+		}
+		res, err = sets.GetSet(ctx, code)
+		return err
+	})
+	return res, err
+}
+
+func (b *Breaker) GetDeck(ctx context.Context, slug string) (fabdb.Deck, error) {
+	var res fabdb.Deck
+	err := b.call(ctx, func(ctx context.Context, s Source) (err error) {
+		decks, ok := s.(DeckSource)
+		if !ok {
+			return unsupported(OpGetDeck)
+		}
+		res, err = decks.GetDeck(ctx, slug)
+		return err
+	})
+	return res, err
+}
+
+func (b *Breaker) call(ctx context.Context, fn func(ctx context.Context, s Source) error) error {
+	if !b.allow() {
+		return b.callFallback(ctx, fn, ErrBreakerOpen)
+	}
+
+	pctx := ctx
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		pctx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+
+	err := fn(pctx, b.primary)
+	failed := isFailure(err)
+	b.record(err, failed)
+
+	if failed && ctx.Err() == nil {
+		return b.callFallback(ctx, fn, err)
+	}
+	return err
+}
+
+// callFallback passes the request to the fallback source, err is returned
+// if there is no fallback or it doesn't support the request
+func (b *Breaker) callFallback(ctx context.Context, fn func(ctx context.Context, s Source) error, err error) error {
+	if b.fallback == nil {
+		return err
+	}
+	if fallbackErr := fn(ctx, b.fallback); !errors.Is(fallbackErr, ErrUnsupported) {
+		return fallbackErr
+	}
+	return err
+}
+
+// allow reports whether a request may be sent to the primary source
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		return true
+	case BreakerHalfOpen:
+		// a probe is in flight already
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *Breaker) record(err error, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if errors.Is(err, context.Canceled) {
+		// the caller went away, the probe did not tell anything about the source
+		if b.state == BreakerHalfOpen {
+			b.setState(BreakerOpen)
+		}
+		return
+	}
+
+	if !failed {
+		b.failures = 0
+		if b.state != BreakerClosed {
+			b.setState(BreakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		if b.state != BreakerOpen {
+			b.setState(BreakerOpen)
+		}
+	}
+}
+
+func (b *Breaker) setState(s BreakerState) {
+	from := b.state
+	b.state = s
+	b.onChange(from, s)
+}
+
+// isFailure reports whether an error indicates that the source is unavailable,
+// as opposed to requests that legitimately have no result. Every API error
+// but not found counts as failure, as do responses that can't be decoded.
+func isFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var notFound fabdb.NotFoundError
+	if errors.As(err, &notFound) {
+		return false
+	}
+
+	var (
+		apiErr       fabdb.APIError
+		decodeErr    fabdb.DecodeError
+		transportErr fabdb.TransportError
+	)
+	if errors.As(err, &apiErr) || errors.As(err, &decodeErr) || errors.As(err, &transportErr) {
+		return true
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,190 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBreakerStates(t *testing.T) {
+	serverErr := fabdb.ServerError{APIError: fabdb.APIError{StatusCode: 503}}
+
+	type step struct {
+		// elapsed is the time passed before the lookup
+		elapsed time.Duration
+		err     error
+		// reached is true if the lookup is passed to the primary source
+		reached bool
+		state   BreakerState
+	}
+
+	tests := []struct {
+		name        string
+		steps       []step
+		transitions []string
+	}{
+		{
+			name: "opens after consecutive failures",
+			steps: []step{
+				{err: serverErr, reached: true, state: BreakerClosed},
+				{err: serverErr, reached: true, state: BreakerOpen},
+				{reached: false, state: BreakerOpen},
+			},
+			transitions: []string{"closed->open"},
+		},
+		{
+			name: "success resets failures",
+			steps: []step{
+				{err: serverErr, reached: true, state: BreakerClosed},
+				{reached: true, state: BreakerClosed},
+				{err: serverErr, reached: true, state: BreakerClosed},
+			},
+		},
+		{
+			name: "not found is no failure",
+			steps: []step{
+				{err: fabdb.NotFoundError{}, reached: true, state: BreakerClosed},
+				{err: fabdb.NotFoundError{}, reached: true, state: BreakerClosed},
+				{err: fabdb.NotFoundError{}, reached: true, state: BreakerClosed},
+			},
+		},
+		{
+			name: "probe closes",
+			steps: []step{
+				{err: serverErr, reached: true, state: BreakerClosed},
+				{err: serverErr, reached: true, state: BreakerOpen},
+				{elapsed: 30 * time.Second, reached: false, state: BreakerOpen},
+				{elapsed: 31 * time.Second, reached: true, state: BreakerClosed},
+			},
+			transitions: []string{"closed->open", "open->half-open", "half-open->closed"},
+		},
+		{
+			name: "failed probe opens again",
+			steps: []step{
+				{err: serverErr, reached: true, state: BreakerClosed},
+				{err: serverErr, reached: true, state: BreakerOpen},
+				{elapsed: time.Minute, err: serverErr, reached: true, state: BreakerOpen},
+				{elapsed: 59 * time.Second, reached: false, state: BreakerOpen},
+			},
+			transitions: []string{"closed->open", "open->half-open", "half-open->open"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+			var transitions []string
+			source := &fakeSource{cards: []fabdb.Card{testCard("snatch-red", "Snatch")}}
+			breaker := NewBreaker(source,
+				WithFailureThreshold(2),
+				WithCooldown(time.Minute),
+				WithStateChangeFunc(func(from, to BreakerState) {
+					transitions = append(transitions, from.String()+"->"+to.String())
+				}),
+			)
+			breaker.now = func() time.Time { return now }
+
+			for i, s := range tt.steps {
+				now = now.Add(s.elapsed)
+				source.setErr(s.err)
+				calls := source.callCount()
+
+				_, err := breaker.GetCard(context.Background(), "snatch-red")
+				if reached := source.callCount() > calls; reached != s.reached {
+					t.Errorf("step %d: GetCard() reached primary = %t, want %t", i+1, reached, s.reached)
+				}
+				if !s.reached && !errors.Is(err, ErrBreakerOpen) {
+					t.Errorf("step %d: GetCard() error = %v, want %v", i+1, err, ErrBreakerOpen)
+				}
+				if got := breaker.State(); got != s.state {
+					t.Errorf("step %d: State() = %s, want %s", i+1, got, s.state)
+				}
+			}
+			if !reflect.DeepEqual(transitions, tt.transitions) {
+				t.Errorf("transitions = %v, want %v", transitions, tt.transitions)
+			}
+		})
+	}
+}
+
+func TestBreakerFallback(t *testing.T) {
+	primary := &fakeSource{err: fabdb.ServerError{APIError: fabdb.APIError{StatusCode: 503}}}
+	fallback := &fakeSource{cards: []fabdb.Card{testCard("snatch-red", "Snatch")}}
+	breaker := NewBreaker(primary, WithFallback(fallback), WithFailureThreshold(1))
+
+	for i := 0; i < 3; i++ {
+		card, err := breaker.GetCard(context.Background(), "snatch-red")
+		if err != nil || card.Identifier != "snatch-red" {
+			t.Errorf("GetCard() = %q, %v, want snatch-red from fallback", card.Identifier, err)
+		}
+	}
+	if got := primary.callCount(); got != 1 {
+		t.Errorf("GetCard() reached primary %d times, want 1", got)
+	}
+	if got := fallback.callCount(); got != 3 {
+		t.Errorf("GetCard() reached fallback %d times, want 3", got)
+	}
+
+	// the fallback doesn't provide sets, they fail fast
+	if _, err := breaker.ListSets(context.Background()); !errors.Is(err, ErrBreakerOpen) {
+		t.Errorf("ListSets() error = %v, want %v", err, ErrBreakerOpen)
+	}
+}
+
+func TestBreakerTimeout(t *testing.T) {
+	source := &fakeSource{block: make(chan struct{})}
+	defer close(source.block)
+	breaker := NewBreaker(source, WithFailureThreshold(1), WithTimeout(10*time.Millisecond))
+
+	if _, err := breaker.GetCard(context.Background(), "snatch-red"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetCard() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if got := breaker.State(); got != BreakerOpen {
+		t.Errorf("State() = %s, want %s", got, BreakerOpen)
+	}
+}
+
+func TestBreakerConcurrentProbe(t *testing.T) {
+	source := &fakeSource{
+		cards: []fabdb.Card{testCard("snatch-red", "Snatch")},
+		err:   fabdb.ServerError{APIError: fabdb.APIError{StatusCode: 503}},
+	}
+	breaker := NewBreaker(source, WithFailureThreshold(1), WithCooldown(0))
+	if _, err := breaker.GetCard(context.Background(), "snatch-red"); err == nil {
+		t.Fatal("GetCard() succeeded, want error")
+	}
+
+	// only one of the concurrent lookups probes the primary source
+	source.setErr(nil)
+	source.block = make(chan struct{})
+	var wg sync.WaitGroup
+	rejected := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := breaker.GetCard(context.Background(), "snatch-red"); err != nil {
+				rejected <- err
+			}
+		}()
+	}
+	// the probe is blocked until all other lookups failed fast
+	for i := 0; i < 9; i++ {
+		if err := <-rejected; !errors.Is(err, ErrBreakerOpen) {
+			t.Errorf("GetCard() error = %v, want %v", err, ErrBreakerOpen)
+		}
+	}
+	close(source.block)
+	wg.Wait()
+
+	if got := source.callCount(); got != 2 {
+		t.Errorf("GetCard() reached primary %d times, want 2", got)
+	}
+	if got := breaker.State(); got != BreakerClosed {
+		t.Errorf("State() = %s, want %s", got, BreakerClosed)
+	}
+}
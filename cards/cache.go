@@ -0,0 +1,338 @@
+package cards
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheTTL         = 10 * time.Minute
+	defaultCacheNegativeTTL = time.Minute
+	defaultCacheSize        = 1000
+)
+
+// CacheMetrics records cache hits and misses per operation
+type CacheMetrics interface {
+	IncCardsCacheHits(op string)
+	IncCardsCacheMisses(op string)
+}
+
+type nopCacheMetrics struct{}
+
+func (nopCacheMetrics) IncCardsCacheHits(string)   {}
+func (nopCacheMetrics) IncCardsCacheMisses(string) {}
+
+// Cache is a read-through cache in front of a card Source. Entries expire
+// after a TTL and the least recently used entries are evicted once the
+// cache is full. Searches without results are cached with a separate TTL.
+// Cached cards are copied including their slices and maps, so that callers
+// can't modify cached results.
+type Cache struct {
+	next        Source
+	ttl         time.Duration
+	negativeTTL time.Duration
+	size        int
+	metrics     CacheMetrics
+	now         func() time.Time
+
+	mu    sync.Mutex
+	lru   *list.List
+	items map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	cards   []fabdb.Card
+	card    fabdb.Card
+	sets    []fabdb.Set
+	set     fabdb.Set
+	deck    fabdb.Deck
+	err     error
+	expires time.Time
+}
+
+// CacheOption passed to NewCache to change the default instance.
+type CacheOption func(c *Cache)
+
+// NewCache returns a Cache in front of the given Source
+func NewCache(next Source, opts ...CacheOption) *Cache {
+	c := &Cache{
+		next:        next,
+		ttl:         defaultCacheTTL,
+		negativeTTL: defaultCacheNegativeTTL,
+		size:        defaultCacheSize,
+		metrics:     nopCacheMetrics{},
+		now:         time.Now,
+		lru:         list.New(),
+		items:       make(map[string]*list.Element),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithTTL sets how long results are cached
+func WithTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithNegativeTTL sets how long empty results are cached. Zero disables negative caching.
+func WithNegativeTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.negativeTTL = ttl
+	}
+}
+
+// WithMaxEntries sets the maximum number of cached results
+func WithMaxEntries(n int) CacheOption {
+	return func(c *Cache) {
+		if n > 0 {
+			c.size = n
+		}
+	}
+}
+
+// WithCacheMetrics sets the metrics backend recording hits and misses
+func WithCacheMetrics(m CacheMetrics) CacheOption {
+	return func(c *Cache) {
+		c.metrics = m
+	}
+}
+
+// ListCards returns the cached result for the query or queries the next Source
+func (c *Cache) ListCards(ctx context.Context, query string) ([]fabdb.Card, error) {
+	key := listKey(query)
+	if e, ok := c.get(key, OpListCards); ok {
+		return copyCards(e.cards), e.err
+	}
+
+	cards, err := c.next.ListCards(ctx, query)
+	c.set(&cacheEntry{key: key, cards: copyCards(cards), err: err})
+	return cards, err
+}
+
+// SearchCards returns the cached result for the search or queries the next Source
+func (c *Cache) SearchCards(ctx context.Context, opts fabdb.SearchOptions) ([]fabdb.Card, error) {
+	key := searchKey(opts)
+	if e, ok := c.get(key, OpSearchCards); ok {
+		return copyCards(e.cards), e.err
+	}
+
+	cards, err := c.next.SearchCards(ctx, opts)
+	c.set(&cacheEntry{key: key, cards: copyCards(cards), err: err})
+	return cards, err
+}
+
+// GetCard returns the cached card or queries the next Source
+func (c *Cache) GetCard(ctx context.Context, identifier string) (fabdb.Card, error) {
+	key := getKey(identifier)
+	if e, ok := c.get(key, OpGetCard); ok {
+		return copyCard(e.card), e.err
+	}
+
+	card, err := c.next.GetCard(ctx, identifier)
+	c.set(&cacheEntry{key: key, card: copyCard(card), err: err})
+	return card, err
+}
+
+// ListSets returns the cached sets or queries the next Source
+func (c *Cache) ListSets(ctx context.Context) ([]fabdb.Set, error) {
+	next, ok := c.next.(SetSource)
+	if !ok {
+		return nil, unsupported(OpListSets)
+	}
+	if e, ok := c.get(OpListSets, OpListSets); ok {
+		return copySets(e.sets), e.err
+	}
+
+	sets, err := next.ListSets(ctx)
+	c.set(&cacheEntry{key: OpListSets, sets: copySets(sets), err: err})
+	return sets, err
+}
+
+// GetSet returns the cached set or queries the next Source
+func (c *Cache) GetSet(ctx context.Context, code string) (fabdb.Set, error) {
+	next, ok := c.next.(SetSource)
+	if !ok {
+		return fabdb.Set{}, unsupported(OpGetSet)
+	}
+	key := setKey(code)
+	if e, ok := c.get(key, OpGetSet); ok {
+		return e.set, e.err
+	}
+
+	set, err := next.GetSet(ctx, code)
+	c.set(&cacheEntry{key: key, set: set, err: err})
+	return set, err
+}
+
+// GetDeck returns the cached deck or queries the next Source
+func (c *Cache) GetDeck(ctx context.Context, slug string) (fabdb.Deck, error) {
+	next, ok := c.next.(DeckSource)
+	if !ok {
+		return fabdb.Deck{}, unsupported(OpGetDeck)
+	}
+	key := deckKey(slug)
+	if e, ok := c.get(key, OpGetDeck); ok {
+		return copyDeck(e.deck), e.err
+	}
+
+	deck, err := next.GetDeck(ctx, slug)
+	c.set(&cacheEntry{key: key, deck: copyDeck(deck), err: err})
+	return deck, err
+}
+
+// Len returns the number of cached entries
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *Cache) get(key, op string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.metrics.IncCardsCacheMisses(op)
+		return nil, false
+	}
+
+	e := el.Value.(*cacheEntry)
+	if c.now().After(e.expires) {
+		c.remove(el)
+		c.metrics.IncCardsCacheMisses(op)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(el)
+	c.metrics.IncCardsCacheHits(op)
+	return e, true
+}
+
+func (c *Cache) set(e *cacheEntry) {
+	ttl := c.ttl
+	if e.err != nil {
+		if !isNegative(e.err) {
+			return
+		}
+		ttl = c.negativeTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+	e.expires = c.now().Add(ttl)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[e.key]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.items[e.key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *Cache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.items, el.Value.(*cacheEntry).key)
+}
+
+// copyCard returns a copy of the card that shares no slices or maps with it
+func copyCard(card fabdb.Card) fabdb.Card {
+	card.Keywords = copyStrings(card.Keywords)
+	card.Subtypes = copyStrings(card.Subtypes)
+	if card.Printings != nil {
+		card.Printings = append(make([]fabdb.Printings, 0, len(card.Printings)), card.Printings...)
+	}
+	if card.Stats != nil {
+		stats := make(fabdb.RawStats, len(card.Stats))
+		for k, v := range card.Stats {
+			stats[k] = v
+		}
+		card.Stats = stats
+	}
+	if card.Extra != nil {
+		extra := make(map[string]json.RawMessage, len(card.Extra))
+		for k, v := range card.Extra {
+			extra[k] = append(json.RawMessage(nil), v...)
+		}
+		card.Extra = extra
+	}
+	return card
+}
+
+// copyCards returns deep copies of the cards, keeping nil as nil
+func copyCards(cards []fabdb.Card) []fabdb.Card {
+	if cards == nil {
+		return nil
+	}
+	res := make([]fabdb.Card, len(cards))
+	for i, card := range cards {
+		res[i] = copyCard(card)
+	}
+	return res
+}
+
+// copyStrings returns a copy of s, keeping nil as nil
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(s)), s...)
+}
+
+// copySets returns a copy of the sets, keeping nil as nil
+func copySets(sets []fabdb.Set) []fabdb.Set {
+	if sets == nil {
+		return nil
+	}
+	return append(make([]fabdb.Set, 0, len(sets)), sets...)
+}
+
+// copyDeck returns the deck with deep copies of its hero and cards
+func copyDeck(deck fabdb.Deck) fabdb.Deck {
+	deck.Hero = copyCard(deck.Hero)
+	deck.Cards = copyDeckCards(deck.Cards)
+	deck.Sideboard = copyDeckCards(deck.Sideboard)
+	return deck
+}
+
+// copyDeckCards returns deep copies of the deck cards, keeping nil as nil
+func copyDeckCards(cards []fabdb.DeckCard) []fabdb.DeckCard {
+	if cards == nil {
+		return nil
+	}
+	res := make([]fabdb.DeckCard, len(cards))
+	for i, c := range cards {
+		res[i] = fabdb.DeckCard{Card: copyCard(c.Card), Total: c.Total}
+	}
+	return res
+}
+
+// isNegative reports whether the error means that there are no results,
+// rather than a failure which must not be cached.
+func isNegative(err error) bool {
+	if errors.Is(err, fabdb.ErrNoCards) || errors.Is(err, fabdb.ErrNoSet) {
+		return true
+	}
+
+	var notFound fabdb.NotFoundError
+	return errors.As(err, &notFound)
+}
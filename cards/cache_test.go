@@ -0,0 +1,249 @@
+package cards
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacheExpiry(t *testing.T) {
+	tests := []struct {
+		name        string
+		ttl         time.Duration
+		negativeTTL time.Duration
+		err         error
+		elapsed     []time.Duration
+		calls       int
+	}{
+		{
+			name:    "cached within ttl",
+			ttl:     10 * time.Minute,
+			elapsed: []time.Duration{0, 5 * time.Minute, 4 * time.Minute},
+			calls:   1,
+		},
+		{
+			name:    "expired",
+			ttl:     10 * time.Minute,
+			elapsed: []time.Duration{0, 11 * time.Minute, time.Minute},
+			calls:   2,
+		},
+		{
+			name:    "caching disabled",
+			ttl:     0,
+			elapsed: []time.Duration{0, 0},
+			calls:   2,
+		},
+		{
+			name:        "negative entry",
+			ttl:         10 * time.Minute,
+			negativeTTL: time.Minute,
+			err:         fabdb.NotFoundError{},
+			elapsed:     []time.Duration{0, 30 * time.Second},
+			calls:       1,
+		},
+		{
+			name:        "negative entry expired",
+			ttl:         10 * time.Minute,
+			negativeTTL: time.Minute,
+			err:         fabdb.NotFoundError{},
+			elapsed:     []time.Duration{0, 2 * time.Minute},
+			calls:       2,
+		},
+		{
+			name:        "negative caching disabled",
+			ttl:         10 * time.Minute,
+			negativeTTL: 0,
+			err:         fabdb.NotFoundError{},
+			elapsed:     []time.Duration{0, 0},
+			calls:       2,
+		},
+		{
+			name:        "failures not cached",
+			ttl:         10 * time.Minute,
+			negativeTTL: time.Minute,
+			err:         fabdb.ServerError{},
+			elapsed:     []time.Duration{0, 0},
+			calls:       2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+			source := &fakeSource{cards: []fabdb.Card{testCard("snatch-red", "Snatch")}, err: tt.err}
+			cache := NewCache(source, WithTTL(tt.ttl), WithNegativeTTL(tt.negativeTTL))
+			cache.now = func() time.Time { return now }
+
+			for _, elapsed := range tt.elapsed {
+				now = now.Add(elapsed)
+				card, err := cache.GetCard(context.Background(), "snatch-red")
+				if (err != nil) != (tt.err != nil) {
+					t.Fatalf("GetCard() error = %v, want %v", err, tt.err)
+				}
+				if tt.err == nil && card.Identifier != "snatch-red" {
+					t.Fatalf("GetCard() = %q, want snatch-red", card.Identifier)
+				}
+			}
+			if got := source.callCount(); got != tt.calls {
+				t.Errorf("GetCard() reached the source %d times, want %d", got, tt.calls)
+			}
+		})
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	source := &fakeSource{cards: []fabdb.Card{
+		testCard("a", "A"),
+		testCard("b", "B"),
+		testCard("c", "C"),
+	}}
+	cache := NewCache(source, WithMaxEntries(2))
+
+	tests := []struct {
+		identifier string
+		calls      int
+	}{
+		{identifier: "a", calls: 1},
+		{identifier: "b", calls: 2},
+		{identifier: "a", calls: 2},
+		// evicts b, the least recently used entry
+		{identifier: "c", calls: 3},
+		{identifier: "a", calls: 3},
+		{identifier: "b", calls: 4},
+	}
+
+	for _, tt := range tests {
+		if _, err := cache.GetCard(context.Background(), tt.identifier); err != nil {
+			t.Fatalf("GetCard(%q) error = %v", tt.identifier, err)
+		}
+		if got := source.callCount(); got != tt.calls {
+			t.Errorf("GetCard(%q) reached the source %d times in total, want %d", tt.identifier, got, tt.calls)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", cache.Len())
+	}
+}
+
+func TestCacheCopies(t *testing.T) {
+	want := testCard("snatch-red", "Snatch")
+
+	// modify changes everything a caller could change in place
+	modify := func(c *fabdb.Card) {
+		c.Keywords[0] = "changed"
+		c.Subtypes[0] = "changed"
+		c.Printings[0].Set = "changed"
+		c.Stats["cost"] = "changed"
+		c.Extra["flavour"] = json.RawMessage(`"changed"`)
+	}
+
+	tests := []struct {
+		name   string
+		lookup func(c *Cache, modify func(c *fabdb.Card)) error
+	}{
+		{
+			name: "get",
+			lookup: func(c *Cache, modify func(c *fabdb.Card)) error {
+				card, err := c.GetCard(context.Background(), "snatch-red")
+				if err != nil || modify == nil {
+					return err
+				}
+				modify(&card)
+				return nil
+			},
+		},
+		{
+			name: "list",
+			lookup: func(c *Cache, modify func(c *fabdb.Card)) error {
+				cards, err := c.ListCards(context.Background(), "snatch")
+				if err != nil || modify == nil {
+					return err
+				}
+				modify(&cards[0])
+				return nil
+			},
+		},
+		{
+			name: "deck",
+			lookup: func(c *Cache, modify func(c *fabdb.Card)) error {
+				deck, err := c.GetDeck(context.Background(), "aBcDeFgH")
+				if err != nil || modify == nil {
+					return err
+				}
+				modify(&deck.Hero)
+				modify(&deck.Cards[0].Card)
+				deck.Sideboard[0].Total = 2
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &fakeSource{
+				cards: []fabdb.Card{testCard("snatch-red", "Snatch")},
+				decks: []fabdb.Deck{{
+					Slug:      "aBcDeFgH",
+					Hero:      testCard("dorinthea", "Dorinthea"),
+					Cards:     []fabdb.DeckCard{{Card: testCard("snatch-red", "Snatch"), Total: 3}},
+					Sideboard: []fabdb.DeckCard{{Card: testCard("snatch-red", "Snatch"), Total: 1}},
+				}},
+			}
+			cache := NewCache(source)
+
+			// the first lookup stores the result, the second one is served from
+			// the cache and modified by the caller
+			if err := tt.lookup(cache, nil); err != nil {
+				t.Fatalf("lookup error = %v", err)
+			}
+			if err := tt.lookup(cache, modify); err != nil {
+				t.Fatalf("lookup error = %v", err)
+			}
+
+			card, err := cache.GetCard(context.Background(), "snatch-red")
+			if err != nil {
+				t.Fatalf("GetCard() error = %v", err)
+			}
+			if !reflect.DeepEqual(card, want) {
+				t.Errorf("GetCard() = %+v, want %+v", card, want)
+			}
+			cards, err := cache.ListCards(context.Background(), "snatch")
+			if err != nil {
+				t.Fatalf("ListCards() error = %v", err)
+			}
+			if !reflect.DeepEqual(cards, []fabdb.Card{want}) {
+				t.Errorf("ListCards() = %+v, want %+v", cards, want)
+			}
+			deck, err := cache.GetDeck(context.Background(), "aBcDeFgH")
+			if err != nil {
+				t.Fatalf("GetDeck() error = %v", err)
+			}
+			if !reflect.DeepEqual(deck, source.decks[0]) {
+				t.Errorf("GetDeck() = %+v, want %+v", deck, source.decks[0])
+			}
+		})
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	source := &fakeSource{cards: []fabdb.Card{testCard("a", "A"), testCard("b", "B")}}
+	cache := NewCache(source, WithMaxEntries(1))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			identifier := []string{"a", "b"}[i%2]
+			card, err := cache.GetCard(context.Background(), identifier)
+			if err != nil || card.Identifier != identifier || card.Keywords[0] != "generic" {
+				t.Errorf("GetCard(%q) = %+v, %v", identifier, card, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,78 @@
+// Package cards contains decorators for card sources, like the fabdb.net API
+// client or a local snapshot, adding caching and resilience.
+package cards
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"strings"
+)
+
+// Source provides card data. It has the same method set as telegram.Cards,
+// so every decorator in this package can be used as card source for the bot.
+type Source interface {
+	ListCards(ctx context.Context, query string) ([]fabdb.Card, error)
+	SearchCards(ctx context.Context, opts fabdb.SearchOptions) ([]fabdb.Card, error)
+	GetCard(ctx context.Context, identifier string) (fabdb.Card, error)
+}
+
+// SetSource provides card sets. The decorators in this package implement it
+// by passing lookups on to the decorated source, if it is a SetSource too.
+type SetSource interface {
+	ListSets(ctx context.Context) ([]fabdb.Set, error)
+	GetSet(ctx context.Context, code string) (fabdb.Set, error)
+}
+
+// DeckSource provides published decks. The decorators in this package
+// implement it by passing lookups on to the decorated source, if it is a
+// DeckSource too.
+type DeckSource interface {
+	GetDeck(ctx context.Context, slug string) (fabdb.Deck, error)
+}
+
+// ErrUnsupported is returned for set and deck lookups if the decorated source doesn't provide them
+var ErrUnsupported = errors.New("lookup not supported by card source")
+
+// Operation names used as metric labels
+const (
+	OpListCards   = "list"
+	OpSearchCards = "search"
+	OpGetCard     = "get"
+	OpListSets    = "sets"
+	OpGetSet      = "set"
+	OpGetDeck     = "deck"
+)
+
+func unsupported(op string) error {
+	return fmt.Errorf("%w: %s", ErrUnsupported, op)
+}
+
+// normalize returns a canonical form of a query, so that queries differing
+// only in case or whitespace share cache entries and in-flight requests.
+func normalize(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+func listKey(query string) string {
+	return OpListCards + ":" + normalize(query)
+}
+
+func searchKey(opts fabdb.SearchOptions) string {
+	opts.Keywords = normalize(opts.Keywords)
+	return OpSearchCards + ":" + strings.ToLower(opts.Encode())
+}
+
+func getKey(identifier string) string {
+	return OpGetCard + ":" + normalize(identifier)
+}
+
+func setKey(code string) string {
+	return OpGetSet + ":" + normalize(code)
+}
+
+func deckKey(slug string) string {
+	// deck slugs are case sensitive
+	return OpGetDeck + ":" + fabdb.DeckSlug(slug)
+}
@@ -0,0 +1,111 @@
+package cards
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"sync"
+)
+
+// fakeSource serves the cards by identifier and name and counts the lookups
+// reaching it. Lookups fail with err if set, wait for block to be closed and
+// panic if panics is set.
+type fakeSource struct {
+	cards []fabdb.Card
+	decks []fabdb.Deck
+
+	mu     sync.Mutex
+	err    error
+	block  chan struct{}
+	panics bool
+	calls  int
+	ctxs   []context.Context
+}
+
+func (f *fakeSource) setErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+func (f *fakeSource) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func (f *fakeSource) lookup(ctx context.Context) error {
+	f.mu.Lock()
+	f.calls++
+	f.ctxs = append(f.ctxs, ctx)
+	err, block, panics := f.err, f.block, f.panics
+	f.mu.Unlock()
+
+	if block != nil {
+		select {
+		case <-block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if panics {
+		panic("lookup failed")
+	}
+	return err
+}
+
+func (f *fakeSource) ListCards(ctx context.Context, query string) ([]fabdb.Card, error) {
+	if err := f.lookup(ctx); err != nil {
+		return nil, err
+	}
+	var cards []fabdb.Card
+	for _, c := range f.cards {
+		if normalize(c.Name) == normalize(query) {
+			cards = append(cards, c)
+		}
+	}
+	if len(cards) == 0 {
+		return nil, fabdb.ErrNoCards
+	}
+	return cards, nil
+}
+
+func (f *fakeSource) SearchCards(ctx context.Context, opts fabdb.SearchOptions) ([]fabdb.Card, error) {
+	return f.ListCards(ctx, opts.Keywords)
+}
+
+func (f *fakeSource) GetCard(ctx context.Context, identifier string) (fabdb.Card, error) {
+	if err := f.lookup(ctx); err != nil {
+		return fabdb.Card{}, err
+	}
+	for _, c := range f.cards {
+		if c.Identifier == identifier {
+			return c, nil
+		}
+	}
+	return fabdb.Card{}, fabdb.NotFoundError{}
+}
+
+func (f *fakeSource) GetDeck(ctx context.Context, slug string) (fabdb.Deck, error) {
+	if err := f.lookup(ctx); err != nil {
+		return fabdb.Deck{}, err
+	}
+	for _, d := range f.decks {
+		if d.Slug == slug {
+			return d, nil
+		}
+	}
+	return fabdb.Deck{}, fabdb.NotFoundError{}
+}
+
+func testCard(identifier, name string) fabdb.Card {
+	return fabdb.Card{
+		Identifier: identifier,
+		Name:       name,
+		Keywords:   []string{"generic", "action"},
+		Stats:      fabdb.RawStats{"cost": "0"},
+		Printings:  []fabdb.Printings{{ID: 1, Set: "WTR"}},
+		Subtypes:   []string{"attack"},
+		Extra:      map[string]json.RawMessage{"flavour": json.RawMessage(`"Mine!"`)},
+	}
+}
@@ -0,0 +1,222 @@
+package cards
+
+import (
+	"context"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"sync"
+	"time"
+)
+
+// defaultCoalesceTimeout limits the duration of a shared lookup, which is
+// not canceled by any of its callers
+const defaultCoalesceTimeout = 30 * time.Second
+
+// CoalesceMetrics records how many lookups were forwarded to the next source
+// and how many shared the result of an identical in-flight lookup.
+type CoalesceMetrics interface {
+	IncCardsRequestsForwarded(op string)
+	IncCardsRequestsCoalesced(op string)
+}
+
+type nopCoalesceMetrics struct{}
+
+func (nopCoalesceMetrics) IncCardsRequestsForwarded(string) {}
+func (nopCoalesceMetrics) IncCardsRequestsCoalesced(string) {}
+
+// Coalescer deduplicates concurrent identical lookups, so that only one
+// request per normalized query is in flight towards the next source at a
+// time. All callers waiting for the same query share its result. The shared
+// lookup is detached from the callers' contexts, so that a caller going away
+// doesn't fail the lookup for the others.
+type Coalescer struct {
+	next    Source
+	metrics CoalesceMetrics
+	timeout time.Duration
+
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done  chan struct{}
+	cards []fabdb.Card
+	card  fabdb.Card
+	sets  []fabdb.Set
+	set   fabdb.Set
+	deck  fabdb.Deck
+	err   error
+}
+
+// CoalesceOption passed to NewCoalescer to change the default instance.
+type CoalesceOption func(c *Coalescer)
+
+// NewCoalescer returns a Coalescer in front of the given Source
+func NewCoalescer(next Source, opts ...CoalesceOption) *Coalescer {
+	c := &Coalescer{
+		next:    next,
+		metrics: nopCoalesceMetrics{},
+		timeout: defaultCoalesceTimeout,
+		calls:   make(map[string]*call),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithCoalesceMetrics sets the metrics backend recording forwarded and coalesced lookups
+func WithCoalesceMetrics(m CoalesceMetrics) CoalesceOption {
+	return func(c *Coalescer) {
+		c.metrics = m
+	}
+}
+
+// WithCoalesceTimeout limits the duration of a shared lookup, 0 disables the timeout
+func WithCoalesceTimeout(d time.Duration) CoalesceOption {
+	return func(c *Coalescer) {
+		c.timeout = d
+	}
+}
+
+func (c *Coalescer) ListCards(ctx context.Context, query string) ([]fabdb.Card, error) {
+	cl, err := c.do(ctx, listKey(query), OpListCards, func(ctx context.Context, cl *call) {
+		cl.cards, cl.err = c.next.ListCards(ctx, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cl.cards, cl.err
+}
+
+func (c *Coalescer) SearchCards(ctx context.Context, opts fabdb.SearchOptions) ([]fabdb.Card, error) {
+	cl, err := c.do(ctx, searchKey(opts), OpSearchCards, func(ctx context.Context, cl *call) {
+		cl.cards, cl.err = c.next.SearchCards(ctx, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cl.cards, cl.err
+}
+
+func (c *Coalescer) GetCard(ctx context.Context, identifier string) (fabdb.Card, error) {
+	cl, err := c.do(ctx, getKey(identifier), OpGetCard, func(ctx context.Context, cl *call) {
+		cl.card, cl.err = c.next.GetCard(ctx, identifier)
+	})
+	if err != nil {
+		return fabdb.Card{}, err
+	}
+	return cl.card, cl.err
+}
+
+func (c *Coalescer) ListSets(ctx context.Context) ([]fabdb.Set, error) {
+	sets, ok := c.next.(SetSource)
+	if !ok {
+		return nil, unsupported(OpListSets)
+	}
+	cl, err := c.do(ctx, OpListSets, OpListSets, func(ctx context.Context, cl *call) {
+		cl.sets, cl.err = sets.ListSets(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cl.sets, cl.err
+}
+
+func (c *Coalescer) GetSet(ctx context.Context, code string) (fabdb.Set, error) {
+	sets, ok := c.next.(SetSource)
+	if !ok {
+		return fabdb.Set{}, unsupported(OpGetSet)
+	}
+	cl, err := c.do(ctx, setKey(code), OpGetSet, func(ctx context.Context, cl *call) {
+		cl.set, cl.err = sets.GetSet(ctx, code)
+	})
+	if err != nil {
+		return fabdb.Set{}, err
+	}
+	return cl.set, cl.err
+}
+
+func (c *Coalescer) GetDeck(ctx context.Context, slug string) (fabdb.Deck, error) {
+	decks, ok := c.next.(DeckSource)
+	if !ok {
+		return fabdb.Deck{}, unsupported(OpGetDeck)
+	}
+	cl, err := c.do(ctx, deckKey(slug), OpGetDeck, func(ctx context.Context, cl *call) {
+		cl.deck, cl.err = decks.GetDeck(ctx, slug)
+	})
+	if err != nil {
+		return fabdb.Deck{}, err
+	}
+	return cl.deck, cl.err
+}
+
+// do executes fn for the first caller of a key and lets every caller of the
+// same key wait for its result, including the first one. fn runs with a
+// context detached from the callers, which keeps the values of the first
+// caller's context but is only canceled by the timeout of the Coalescer.
+// The returned error is only set if the caller's context is done while
+// waiting.
+func (c *Coalescer) do(ctx context.Context, key, op string, fn func(ctx context.Context, cl *call)) (*call, error) {
+	c.mu.Lock()
+	cl, ok := c.calls[key]
+	if !ok {
+		cl = &call{done: make(chan struct{})}
+		c.calls[key] = cl
+	}
+	c.mu.Unlock()
+
+	if ok {
+		c.metrics.IncCardsRequestsCoalesced(op)
+	} else {
+		c.metrics.IncCardsRequestsForwarded(op)
+		go c.run(detach(ctx), key, op, cl, fn)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-cl.done:
+		return cl, nil
+	}
+}
+
+// run executes fn for a shared call and releases its waiting callers, even
+// if fn panics
+func (c *Coalescer) run(ctx context.Context, key, op string, cl *call, fn func(ctx context.Context, cl *call)) {
+	defer close(cl.done)
+	defer func() {
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			cl.err = fmt.Errorf("card source panicked on %s: %v", op, r)
+		}
+	}()
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	fn(ctx, cl)
+}
+
+// detachedContext keeps the values of its parent but is never canceled
+type detachedContext struct {
+	parent context.Context
+}
+
+// detach returns a context with the values of ctx, which is not canceled when ctx is
+func detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (d detachedContext) Value(key interface{}) interface{} { return d.parent.Value(key) }
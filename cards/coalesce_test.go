@@ -0,0 +1,143 @@
+package cards
+
+import (
+	"context"
+	"errors"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingCoalesceMetrics struct {
+	forwarded, coalesced int32
+}
+
+func (m *countingCoalesceMetrics) IncCardsRequestsForwarded(string) { atomic.AddInt32(&m.forwarded, 1) }
+func (m *countingCoalesceMetrics) IncCardsRequestsCoalesced(string) { atomic.AddInt32(&m.coalesced, 1) }
+
+// waitFor polls cond until it is true
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCoalescerShared(t *testing.T) {
+	source := &fakeSource{cards: []fabdb.Card{testCard("snatch-red", "Snatch")}, block: make(chan struct{})}
+	metrics := &countingCoalesceMetrics{}
+	c := NewCoalescer(source, WithCoalesceMetrics(metrics))
+
+	queries := []string{"Snatch", "snatch", " SNATCH ", "snatch"}
+	var wg sync.WaitGroup
+	for _, q := range queries {
+		wg.Add(1)
+		go func(q string) {
+			defer wg.Done()
+			cards, err := c.ListCards(context.Background(), q)
+			if err != nil || len(cards) != 1 {
+				t.Errorf("ListCards(%q) = %v, %v, want snatch-red", q, cards, err)
+			}
+		}(q)
+	}
+
+	waitFor(t, func() bool {
+		return atomic.LoadInt32(&metrics.forwarded)+atomic.LoadInt32(&metrics.coalesced) == int32(len(queries))
+	})
+	close(source.block)
+	wg.Wait()
+
+	if got := source.callCount(); got != 1 {
+		t.Errorf("ListCards() reached the source %d times, want 1", got)
+	}
+	if got := atomic.LoadInt32(&metrics.coalesced); got != int32(len(queries)-1) {
+		t.Errorf("ListCards() coalesced %d lookups, want %d", got, len(queries)-1)
+	}
+
+	// lookups after the shared one are forwarded again
+	if _, err := c.ListCards(context.Background(), "snatch"); err != nil {
+		t.Errorf("ListCards() error = %v", err)
+	}
+	if got := source.callCount(); got != 2 {
+		t.Errorf("ListCards() reached the source %d times, want 2", got)
+	}
+}
+
+type ctxKey struct{}
+
+func TestCoalescerDetachedContext(t *testing.T) {
+	source := &fakeSource{cards: []fabdb.Card{testCard("snatch-red", "Snatch")}, block: make(chan struct{})}
+	metrics := &countingCoalesceMetrics{}
+	c := NewCoalescer(source, WithCoalesceMetrics(metrics))
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "first"))
+	first := make(chan error)
+	go func() {
+		_, err := c.GetCard(ctx, "snatch-red")
+		first <- err
+	}()
+	waitFor(t, func() bool { return source.callCount() == 1 })
+
+	second := make(chan error)
+	go func() {
+		card, err := c.GetCard(context.Background(), "snatch-red")
+		if err == nil && card.Identifier != "snatch-red" {
+			err = errors.New("unexpected card " + card.Identifier)
+		}
+		second <- err
+	}()
+	waitFor(t, func() bool { return atomic.LoadInt32(&metrics.coalesced) == 1 })
+
+	// the first caller goes away, the shared lookup goes on for the second
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("GetCard() error = %v, want %v", err, context.Canceled)
+	}
+
+	source.mu.Lock()
+	lookupCtx := source.ctxs[0]
+	source.mu.Unlock()
+	if lookupCtx.Err() != nil {
+		t.Errorf("lookup context error = %v, want nil", lookupCtx.Err())
+	}
+	if got := lookupCtx.Value(ctxKey{}); got != "first" {
+		t.Errorf("lookup context value = %v, want first", got)
+	}
+
+	close(source.block)
+	if err := <-second; err != nil {
+		t.Errorf("GetCard() error = %v", err)
+	}
+}
+
+func TestCoalescerPanic(t *testing.T) {
+	source := &fakeSource{panics: true}
+	c := NewCoalescer(source)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.GetCard(context.Background(), "snatch-red")
+		if err == nil || !strings.Contains(err.Error(), "panicked") {
+			t.Errorf("GetCard() error = %v, want panic error", err)
+		}
+	}
+	if got := source.callCount(); got != 2 {
+		t.Errorf("GetCard() reached the source %d times, want 2", got)
+	}
+}
+
+func TestCoalescerTimeout(t *testing.T) {
+	source := &fakeSource{block: make(chan struct{})}
+	defer close(source.block)
+	c := NewCoalescer(source, WithCoalesceTimeout(10*time.Millisecond))
+
+	if _, err := c.GetCard(context.Background(), "snatch-red"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetCard() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"github.com/cbrgm/fabtcg-bot/cards"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"github.com/cbrgm/fabtcg-bot/metrics"
+	"github.com/cbrgm/fabtcg-bot/snapshot"
+	"github.com/cbrgm/fabtcg-bot/telegram"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"sync"
+)
+
+// sources are the data sources of the bot
+type sources struct {
+	cards telegram.Cards
+	sets  telegram.Sets
+	decks telegram.Decks
+}
+
+// catalog provides cards and sets, like the fabdb.net API, snapshots and the
+// decorators in front of them
+type catalog interface {
+	cards.Source
+	cards.SetSource
+}
+
+// newSources returns the card and set sources used by the bot, either the
+// fabdb.net API or a local snapshot. Lookups against the API are sent through
+// a circuit breaker and request coalescing, and cached if enabled. Decks are
+// always looked up on fabdb.net, as snapshots don't contain them.
+func newSources(logger log.Logger, cli cliRun, prom *metrics.Prometheus) (sources, error) {
+	client := fabdb.NewClient(
+		fabdb.WithRetryPolicy(fabdb.RetryPolicy{
+			MaxAttempts: cli.RetryAttempts,
+			BaseDelay:   cli.RetryBaseDelay,
+			MaxDelay:    cli.RetryMaxDelay,
+		}),
+		fabdb.WithRateLimit(fabdb.RateLimit{
+			PerSecond: cli.RateLimit,
+			Burst:     cli.RateLimitBurst,
+			Reject:    cli.RateLimitReject,
+		}),
+		fabdb.WithConditionalRequests(cli.ConditionalCache),
+		fabdb.WithMaxResponseSize(cli.MaxResponseSize),
+		fabdb.WithSchemaDriftHandler(newDriftHandler(logger, prom)),
+		fabdb.WithHTTPMetrics(prom),
+	)
+
+	api := fabdb.NewFabDBClient(fabdb.WithClient(client))
+
+	breakerOpts := []cards.BreakerOption{
+		cards.WithFailureThreshold(cli.BreakerThreshold),
+		cards.WithCooldown(cli.BreakerCooldown),
+		cards.WithTimeout(cli.Timeout),
+		cards.WithStateChangeFunc(func(from, to cards.BreakerState) {
+			level.Warn(logger).Log("msg", "fabdb circuit breaker changed state", "from", from, "to", to)
+		}),
+	}
+
+	var source catalog
+	if cli.Snapshot != "" {
+		store, err := snapshot.Load(cli.Snapshot)
+		if err != nil {
+			return sources{}, err
+		}
+		level.Info(logger).Log("msg", "serving cards from snapshot", "path", cli.Snapshot, "cards", store.Len())
+		source = store
+	} else if cli.Fallback != "" {
+		store, err := snapshot.Load(cli.Fallback)
+		if err != nil {
+			return sources{}, err
+		}
+		level.Info(logger).Log("msg", "using snapshot as fallback", "path", cli.Fallback, "cards", store.Len())
+		breakerOpts = append(breakerOpts, cards.WithFallback(store))
+	}
+
+	upstream := cards.NewCoalescer(
+		cards.NewBreaker(api, breakerOpts...),
+		cards.WithCoalesceMetrics(prom),
+	)
+	if source == nil {
+		source = upstream
+	}
+	var decks cards.DeckSource = upstream
+
+	if cli.CacheTTL > 0 {
+		cacheOpts := []cards.CacheOption{
+			cards.WithTTL(cli.CacheTTL),
+			cards.WithNegativeTTL(cli.CacheNegativeTTL),
+			cards.WithMaxEntries(cli.CacheSize),
+			cards.WithCacheMetrics(prom),
+		}
+
+		cache := cards.NewCache(source, cacheOpts...)
+		decks = cache
+		if cli.Snapshot != "" {
+			decks = cards.NewCache(upstream, cacheOpts...)
+		}
+		source = cache
+	}
+
+	return sources{cards: source, sets: source, decks: decks}, nil
+}
+
+// newDriftHandler returns a schema drift handler counting drift in metrics.
+// Unknown fields are counted as field "other", their names are chosen by
+// fabdb.net and would add a label value each. Every kind of drift is logged
+// once, type mismatches as warning and unknown fields on debug level.
+func newDriftHandler(logger log.Logger, prom *metrics.Prometheus) fabdb.DriftHandler {
+	var seen sync.Map
+	return func(d fabdb.SchemaDrift) {
+		field := d.Field
+		if d.Kind == fabdb.DriftUnknownField {
+			field = "other"
+		}
+		prom.IncFabDBSchemaDrift(d.Resource, field, d.Kind)
+
+		if _, loaded := seen.LoadOrStore(d.Resource+"."+d.Field+"."+d.Kind, true); loaded {
+			return
+		}
+
+		lvl := level.Warn(logger)
+		if d.Kind == fabdb.DriftUnknownField {
+			lvl = level.Debug(logger)
+		}
+		lvl.Log("msg", "fabdb response does not match expected schema", "drift", d.String())
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/cbrgm/fabtcg-bot/legality"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// newValidator returns the deck legality validator, checking the banned
+// and restricted list file if configured or the list shipped with the bot.
+func newValidator(logger log.Logger, cli cliRun) (*legality.Validator, error) {
+	if cli.LegalityList == "" {
+		validator := legality.NewValidator()
+		level.Info(logger).Log("msg", "using default banned and restricted list", "version", validator.List().Version)
+		return validator, nil
+	}
+
+	list, err := legality.LoadList(cli.LegalityList)
+	if err != nil {
+		return nil, err
+	}
+	level.Info(logger).Log("msg", "loaded banned and restricted list", "path", cli.LegalityList, "version", list.Version, "formats", len(list.Formats))
+	return legality.NewValidator(legality.WithList(list)), nil
+}
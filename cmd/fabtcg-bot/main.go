@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"github.com/alecthomas/kong"
-	"github.com/cbrgm/fabtcg-bot/fabdb"
 	"github.com/cbrgm/fabtcg-bot/metrics"
 	"github.com/cbrgm/fabtcg-bot/telegram"
 	"github.com/go-kit/kit/log"
@@ -37,13 +36,58 @@ var (
 )
 
 var cli struct {
-	HttpAddr string `name:"http.addr" default:"0.0.0.0:8080" help:"The address the fabtcg-bot metrics are exposed"`
 	LogLevel string `name:"log.level" default:"info" enum:"error,warn,info,debug" help:"The log level to use for filtering logs"`
 
+	Run      cliRun      `cmd:"" default:"withargs" help:"Run the telegram bot (default)"`
+	Snapshot cliSnapshot `cmd:"" help:"Manage local card snapshots"`
+}
+
+type cliRun struct {
+	HttpAddr string `name:"http.addr" default:"0.0.0.0:8080" help:"The address the fabtcg-bot metrics are exposed"`
+
 	cliTelegram
+	cliFabDB
+	cliCache
+	cliLegality
 	cliMetrics
 }
 
+type cliSnapshot struct {
+	Pull cliSnapshotPull `cmd:"" help:"Pull the complete card catalogue from fabdb.net into a snapshot file"`
+}
+
+type cliSnapshotPull struct {
+	Output  string        `name:"output" short:"o" default:"cards.snapshot.json" help:"The file the snapshot is written to"`
+	PerPage int           `name:"per-page" default:"100" help:"The number of cards fetched per request"`
+	Timeout time.Duration `name:"timeout" default:"10m" help:"The maximum duration of the pull"`
+}
+
+type cliFabDB struct {
+	Snapshot         string        `name:"fabdb.snapshot" type:"existingfile" help:"Serve cards from a local snapshot file instead of the fabdb.net API"`
+	RetryAttempts    int           `name:"fabdb.retry.attempts" default:"3" help:"The maximum number of attempts for failed fabdb.net requests"`
+	RetryBaseDelay   time.Duration `name:"fabdb.retry.base-delay" default:"200ms" help:"The backoff before the first retry, doubled on every attempt"`
+	RetryMaxDelay    time.Duration `name:"fabdb.retry.max-delay" default:"5s" help:"The maximum backoff between two attempts, 0 disables the cap"`
+	RateLimit        float64       `name:"fabdb.rate-limit" default:"10" help:"The maximum number of fabdb.net requests per second, 0 disables rate limiting"`
+	RateLimitBurst   int           `name:"fabdb.rate-limit.burst" default:"20" help:"The number of fabdb.net requests that may be sent at once"`
+	RateLimitReject  bool          `name:"fabdb.rate-limit.reject" help:"Reject requests exceeding the rate limit instead of queueing them"`
+	ConditionalCache int           `name:"fabdb.conditional-cache" default:"500" help:"The number of fabdb.net responses revalidated using ETag and Last-Modified, 0 disables conditional requests"`
+	MaxResponseSize  int64         `name:"fabdb.max-response-size" default:"16777216" help:"The maximum size of fabdb.net responses in bytes, 0 disables the limit"`
+	Fallback         string        `name:"fabdb.fallback" type:"existingfile" help:"Serve cards from a local snapshot file while fabdb.net is unavailable"`
+	Timeout          time.Duration `name:"fabdb.timeout" default:"5s" help:"The maximum duration of a fabdb.net card lookup, 0 disables the timeout"`
+	BreakerThreshold int           `name:"fabdb.breaker.threshold" default:"5" help:"The number of consecutive fabdb.net failures opening the circuit breaker"`
+	BreakerCooldown  time.Duration `name:"fabdb.breaker.cooldown" default:"30s" help:"How long the circuit breaker stays open before probing fabdb.net again"`
+}
+
+type cliCache struct {
+	CacheTTL         time.Duration `name:"cache.ttl" default:"10m" help:"How long card lookups are cached, 0 disables caching"`
+	CacheNegativeTTL time.Duration `name:"cache.negative-ttl" default:"1m" help:"How long lookups without results are cached, 0 disables negative caching"`
+	CacheSize        int           `name:"cache.size" default:"1000" help:"The maximum number of cached card lookups"`
+}
+
+type cliLegality struct {
+	LegalityList string `name:"legality.list" type:"existingfile" help:"A JSON file listing banned, restricted, living legend and suspended cards per format, replacing the list shipped with the bot"`
+}
+
 type cliMetrics struct {
 	EnableProfiling      bool   `name:"metrics.profile" default:"true" help:"Enable pprof profiling"`
 	EnableRuntimeMetrics bool   `name:"metrics.runtime" default:"true" help:"Enable bot runtime metrics"`
@@ -57,7 +101,7 @@ type cliTelegram struct {
 }
 
 func main() {
-	_ = kong.Parse(&cli,
+	kctx := kong.Parse(&cli,
 		kong.Name("fabtcg-bot"),
 	)
 
@@ -75,6 +119,18 @@ func main() {
 		"caller", log.DefaultCaller,
 	)
 
+	switch kctx.Command() {
+	case "snapshot pull":
+		if err := pullSnapshot(logger, cli.Snapshot.Pull); err != nil {
+			level.Error(logger).Log("msg", "failed to pull card snapshot", "err", err)
+			os.Exit(1)
+		}
+	default:
+		runBot(logger, cli.Run)
+	}
+}
+
+func runBot(logger log.Logger, cli cliRun) {
 	metricOptions := metrics.Options{
 		Enabled:              cli.EnableMetrics,
 		Prefix:               cli.MetricsPrefix,
@@ -91,14 +147,28 @@ func main() {
 
 		token := cli.Token
 		allowlist := cli.Admins
-		client := fabdb.NewFabDBClient()
 
-		bot, err := telegram.NewBot(client, token,
+		sources, err := newSources(tlogger, cli, prom)
+		if err != nil {
+			level.Error(tlogger).Log("msg", "failed to initialize card sources", "err", err)
+			os.Exit(2)
+		}
+
+		validator, err := newValidator(tlogger, cli)
+		if err != nil {
+			level.Error(tlogger).Log("msg", "failed to load banned and restricted list", "err", err)
+			os.Exit(2)
+		}
+
+		bot, err := telegram.NewBot(sources.cards, token,
 			telegram.WithLogger(tlogger),
 			telegram.WithMetrics(prom),
 			telegram.WithAllowlist(allowlist...),
 			telegram.WithStartTime(StartTime),
 			telegram.WithRevision(Revision),
+			telegram.WithSets(sources.sets),
+			telegram.WithDecks(sources.decks),
+			telegram.WithValidator(validator),
 		)
 		if err != nil {
 			level.Error(tlogger).Log("msg", "failed to initialize telegram bot", "err", err)
@@ -143,7 +213,7 @@ func main() {
 		})
 	}
 	{
-		sig := make(chan os.Signal)
+		sig := make(chan os.Signal, 1)
 		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
 
 		gr.Add(func() error {
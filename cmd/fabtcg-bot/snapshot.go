@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"github.com/cbrgm/fabtcg-bot/snapshot"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"os"
+)
+
+// maxSnapshotPages limits the number of pages revalidated by a snapshot pull
+const maxSnapshotPages = 10000
+
+func pullSnapshot(logger log.Logger, cli cliSnapshotPull) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cli.Timeout)
+	defer cancel()
+
+	// revalidate the pages of the previous snapshot instead of downloading them again
+	previous, err := snapshot.Read(cli.Output)
+	switch {
+	case err == nil:
+		level.Info(logger).Log("msg", "revalidating previous snapshot", "path", cli.Output, "pages", len(previous.Responses))
+	case errors.Is(err, os.ErrNotExist):
+		previous = nil
+	default:
+		level.Warn(logger).Log("msg", "ignoring previous snapshot", "path", cli.Output, "err", err)
+		previous = nil
+	}
+
+	level.Info(logger).Log("msg", "pulling card snapshot from fabdb", "per_page", cli.PerPage)
+
+	client := fabdb.NewFabDBClient(
+		fabdb.WithClient(fabdb.NewClient(fabdb.WithConditionalRequests(maxSnapshotPages))),
+		fabdb.WithPageSize(cli.PerPage),
+	)
+	snap, err := snapshot.Pull(ctx, client, previous)
+	if err != nil {
+		return err
+	}
+
+	if err := snap.Write(cli.Output); err != nil {
+		return err
+	}
+
+	level.Info(logger).Log(
+		"msg", "wrote card snapshot",
+		"path", cli.Output,
+		"cards", len(snap.Cards),
+		"checksum", snap.Checksum,
+	)
+	return nil
+}
@@ -0,0 +1,232 @@
+// Package decklist parses plain text decklists, as they are shared in chats
+// or exported by deck builders, and resolves them to fabdb.net cards.
+//
+//	Hero: Dorinthea Ironsong
+//	Weapons: Dawnblade
+//	Equipment: Braveforge Bracers, Helm of Isen's Peak
+//	3x Command and Conquer (Red)
+//	(3) Ironsong Response (red)
+//	Sideboard:
+//	2x Sink Below (Red)
+package decklist
+
+import (
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Section is the part of a deck a line belongs to
+type Section int
+
+const (
+	SectionMain Section = iota
+	SectionSideboard
+	SectionHero
+)
+
+func (s Section) String() string {
+	switch s {
+	case SectionSideboard:
+		return "sideboard"
+	case SectionHero:
+		return "hero"
+	default:
+		return "main"
+	}
+}
+
+// Line is a card line of a decklist, e.g. "3x Command and Conquer (Red)"
+type Line struct {
+	// Number is the line number in the decklist, starting at 1
+	Number int
+	// Text is the line as written in the decklist
+	Text    string
+	Section Section
+	Count   int
+	Name    string
+	// Pitch is the pitch value given for the card, 0 if none was given
+	Pitch int
+}
+
+// Decklist is a parsed plain text decklist
+type Decklist struct {
+	Name   string
+	Format string
+	Lines  []Line
+	// Invalid are the lines that are neither a card nor a known header
+	Invalid []Line
+}
+
+var (
+	headerPattern     = regexp.MustCompile(`^([A-Za-z][A-Za-z ]*):\s*(.*)$`)
+	countPattern      = regexp.MustCompile(`^\(?(\d+)\)?\s*[xX×]?\s+(.+)$`)
+	countAfterPattern = regexp.MustCompile(`^(.+?)\s+[xX×]\s?(\d+)$`)
+	pitchPattern      = regexp.MustCompile(`(?i)\s*[(\[](red|yellow|blue|1|2|3)[)\]]$`)
+)
+
+// maxCount is the highest number of copies accepted for a line, higher
+// counts are most likely typos
+const maxCount = 10
+
+// pitches maps the pitch notations of decklists to pitch values
+var pitches = map[string]int{
+	"red":    1,
+	"yellow": 2,
+	"blue":   3,
+	"1":      1,
+	"2":      2,
+	"3":      3,
+}
+
+// sectionHeaders are lines without colon starting a section, as used by fabrary.net
+var sectionHeaders = map[string]Section{
+	"arena cards": SectionMain,
+	"deck cards":  SectionMain,
+	"main deck":   SectionMain,
+	"sideboard":   SectionSideboard,
+}
+
+// formatNames are the display names of the formats used by fabdb.net
+var formatNames = map[string]string{
+	"blitz":       "Blitz",
+	"constructed": "Classic Constructed",
+	"commoner":    "Commoner",
+	"open":        "Open",
+}
+
+// ignoredPrefixes are lines added by deck builders that don't describe cards
+var ignoredPrefixes = []string{
+	"#",
+	"//",
+	"deck built with",
+	"made with",
+	"see the full deck",
+}
+
+// Parse parses a plain text decklist. Cards are expected one per line with
+// their number of copies, like "3x Name (Red)", "3 Name", "(3) Name" or
+// "Name x3". Hero, weapons and equipment can be listed in headers, a
+// "Sideboard:" line starts the sideboard.
+func Parse(text string) Decklist {
+	var list Decklist
+	section := SectionMain
+
+	for i, raw := range strings.Split(text, "\n") {
+		s := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(raw), "-*•"))
+		if s == "" || ignored(s) {
+			continue
+		}
+		number := i + 1
+
+		if s, ok := sectionHeaders[strings.ToLower(s)]; ok {
+			section = s
+			continue
+		}
+
+		if m := headerPattern.FindStringSubmatch(s); m != nil {
+			value := strings.TrimSpace(m[2])
+			switch strings.ToLower(strings.TrimSpace(m[1])) {
+			case "hero":
+				if line, ok := parseCard(number, value, SectionHero, true); ok {
+					list.Lines = append(list.Lines, line)
+				} else {
+					list.Invalid = append(list.Invalid, Line{Number: number, Text: s, Section: SectionHero})
+				}
+				continue
+			case "name", "deck", "deck name", "title":
+				if value != "" {
+					list.Name = value
+					continue
+				}
+				section = SectionMain
+				continue
+			case "format":
+				list.Format = NormalizeFormat(value)
+				continue
+			case "weapon", "weapons", "equipment":
+				for _, name := range strings.Split(value, ",") {
+					if strings.TrimSpace(name) == "" {
+						continue
+					}
+					if line, ok := parseCard(number, name, section, true); ok {
+						list.Lines = append(list.Lines, line)
+					} else {
+						list.Invalid = append(list.Invalid, Line{Number: number, Text: s, Section: section})
+					}
+				}
+				continue
+			case "sideboard", "side board", "side":
+				section = SectionSideboard
+				continue
+			case "main", "main deck", "maindeck", "cards":
+				section = SectionMain
+				continue
+			case "class", "talent", "notes":
+				continue
+			}
+		}
+
+		if line, ok := parseCard(number, s, section, false); ok {
+			list.Lines = append(list.Lines, line)
+			continue
+		}
+		list.Invalid = append(list.Invalid, Line{Number: number, Text: s, Section: section})
+	}
+
+	return list
+}
+
+// parseCard parses a card line. Without an explicit count a single copy
+// is assumed if single is true, otherwise the line is invalid.
+func parseCard(number int, text string, section Section, single bool) (Line, bool) {
+	text = strings.TrimSpace(text)
+	line := Line{Number: number, Text: text, Section: section, Count: 1, Name: text}
+
+	if m := countPattern.FindStringSubmatch(text); m != nil {
+		line.Count, _ = strconv.Atoi(m[1])
+		line.Name = m[2]
+	} else if m := countAfterPattern.FindStringSubmatch(text); m != nil {
+		line.Count, _ = strconv.Atoi(m[2])
+		line.Name = m[1]
+	} else if !single {
+		return Line{}, false
+	}
+
+	if m := pitchPattern.FindStringSubmatch(line.Name); m != nil {
+		line.Pitch = pitches[strings.ToLower(m[1])]
+		line.Name = line.Name[:len(line.Name)-len(m[0])]
+	}
+	line.Name = strings.TrimSpace(line.Name)
+
+	return line, line.Count > 0 && line.Count <= maxCount && line.Name != ""
+}
+
+func ignored(text string) bool {
+	lower := strings.ToLower(text)
+	for _, prefix := range ignoredPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return strings.Contains(lower, "://")
+}
+
+// NormalizeFormat returns the fabdb.net name of a format, e.g. "constructed" for "CC"
+func NormalizeFormat(format string) string {
+	switch f := strings.ToLower(strings.TrimSpace(format)); f {
+	case "cc", "classic constructed", "classic", "constructed":
+		return "constructed"
+	default:
+		return f
+	}
+}
+
+// FormatName returns the display name of a format, e.g. "Classic Constructed" for "constructed"
+func FormatName(format string) string {
+	if name, ok := formatNames[NormalizeFormat(format)]; ok {
+		return name
+	}
+	return fabdb.Title(format)
+}
@@ -0,0 +1,252 @@
+package decklist
+
+import (
+	"context"
+	"errors"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		list    Decklist
+		invalid []int
+	}{
+		{
+			name: "headers and counts",
+			text: "Name: Dori Aggro\nFormat: CC\nHero: Dorinthea Ironsong\nWeapons: Dawnblade\n3x Command and Conquer (Red)\n(2) Ironsong Response [yellow]\nSink Below x1\nSideboard:\n2 Sink Below (3)",
+			list: Decklist{
+				Name:   "Dori Aggro",
+				Format: "constructed",
+				Lines: []Line{
+					{Number: 3, Text: "Dorinthea Ironsong", Section: SectionHero, Count: 1, Name: "Dorinthea Ironsong"},
+					{Number: 4, Text: "Dawnblade", Section: SectionMain, Count: 1, Name: "Dawnblade"},
+					{Number: 5, Text: "3x Command and Conquer (Red)", Section: SectionMain, Count: 3, Name: "Command and Conquer", Pitch: 1},
+					{Number: 6, Text: "(2) Ironsong Response [yellow]", Section: SectionMain, Count: 2, Name: "Ironsong Response", Pitch: 2},
+					{Number: 7, Text: "Sink Below x1", Section: SectionMain, Count: 1, Name: "Sink Below"},
+					{Number: 9, Text: "2 Sink Below (3)", Section: SectionSideboard, Count: 2, Name: "Sink Below", Pitch: 3},
+				},
+			},
+		},
+		{
+			name: "section headers without colon",
+			text: "Deck cards\n1 Snatch (Red)\nSideboard\n1 Snatch (Blue)",
+			list: Decklist{
+				Lines: []Line{
+					{Number: 2, Text: "1 Snatch (Red)", Section: SectionMain, Count: 1, Name: "Snatch", Pitch: 1},
+					{Number: 4, Text: "1 Snatch (Blue)", Section: SectionSideboard, Count: 1, Name: "Snatch", Pitch: 3},
+				},
+			},
+		},
+		{
+			name: "ignored lines",
+			text: "# comment\n// comment\nMade with fabrary.net\nhttps://fabdb.net/decks/abc\n\n- 1x Snatch (Red)",
+			list: Decklist{
+				Lines: []Line{
+					{Number: 6, Text: "1x Snatch (Red)", Section: SectionMain, Count: 1, Name: "Snatch", Pitch: 1},
+				},
+			},
+		},
+		{
+			name:    "line without count",
+			text:    "Snatch (Red)",
+			invalid: []int{1},
+		},
+		{
+			name:    "invalid hero",
+			text:    "Hero: 11x Dorinthea Ironsong",
+			invalid: []int{1},
+		},
+		{
+			name:    "count above limit",
+			text:    "1x Snatch (Red)\n11x Snatch (Blue)\n0x Sink Below (Red)",
+			invalid: []int{2, 3},
+			list: Decklist{
+				Lines: []Line{
+					{Number: 1, Text: "1x Snatch (Red)", Section: SectionMain, Count: 1, Name: "Snatch", Pitch: 1},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := Parse(tt.text)
+
+			var invalid []int
+			for _, l := range list.Invalid {
+				invalid = append(invalid, l.Number)
+			}
+			if !reflect.DeepEqual(invalid, tt.invalid) {
+				t.Errorf("Parse() invalid lines = %v, want %v", invalid, tt.invalid)
+			}
+
+			list.Invalid = nil
+			if !reflect.DeepEqual(list, tt.list) {
+				t.Errorf("Parse() = %+v, want %+v", list, tt.list)
+			}
+		})
+	}
+}
+
+// fakeCards looks up the cards by identifier and searches them by name
+type fakeCards []fabdb.Card
+
+func (f fakeCards) GetCard(_ context.Context, identifier string) (fabdb.Card, error) {
+	for _, c := range f {
+		if c.Identifier == identifier {
+			return c, nil
+		}
+	}
+	return fabdb.Card{}, fabdb.NotFoundError{}
+}
+
+func (f fakeCards) ListCards(_ context.Context, query string) ([]fabdb.Card, error) {
+	var cards []fabdb.Card
+	for _, c := range f {
+		if strings.Contains(strings.ToLower(c.Name), query) {
+			cards = append(cards, c)
+		}
+	}
+	if len(cards) == 0 {
+		return nil, fabdb.ErrNoCards
+	}
+	return cards, nil
+}
+
+func TestResolve(t *testing.T) {
+	cards := fakeCards{
+		{Identifier: "dorinthea-ironsong", Name: "Dorinthea Ironsong", Type: "hero"},
+		{Identifier: "helm-of-isens-peak", Name: "Helm of Isen's Peak", Type: "equipment"},
+		{Identifier: "snatch-red", Name: "Snatch", Pitch: fabdb.NewStat(1)},
+		{Identifier: "snatch-blue", Name: "Snatch", Pitch: fabdb.NewStat(3)},
+		{Identifier: "fyendals-spring-tunic", Name: "Fyendal’s Spring Tunic", Type: "equipment"},
+	}
+
+	tests := []struct {
+		name      string
+		text      string
+		hero      string
+		cards     map[string]int
+		sideboard map[string]int
+		unmatched map[int]error
+	}{
+		{
+			name:      "by identifier",
+			text:      "Hero: Dorinthea Ironsong\nEquipment: Helm of Isen's Peak\n2x Snatch (Red)\n1x Snatch (Red)\nSideboard:\n3x Snatch (Blue)",
+			hero:      "dorinthea-ironsong",
+			cards:     map[string]int{"helm-of-isens-peak": 1, "snatch-red": 3},
+			sideboard: map[string]int{"snatch-blue": 3},
+		},
+		{
+			name:  "by name",
+			text:  "1x Fyendal's Spring Tunic",
+			cards: map[string]int{"fyendals-spring-tunic": 1},
+		},
+		{
+			name: "unmatched lines",
+			text: "Hero: Dorinthea Ironsong\nHero: Dorinthea Ironsong\n3x Snatch\n1x Snatch (Yellow)\n1x Sink Below (Red)\nSnatch",
+			hero: "dorinthea-ironsong",
+			unmatched: map[int]error{
+				2: ErrSecondHero,
+				3: ErrAmbiguous,
+				4: ErrNoMatch,
+				5: ErrNoMatch,
+				6: ErrInvalidLine,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Resolve(context.Background(), cards, Parse(tt.text))
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+
+			if res.Deck.Hero.Identifier != tt.hero {
+				t.Errorf("Resolve() hero = %q, want %q", res.Deck.Hero.Identifier, tt.hero)
+			}
+			if got := totals(res.Deck.Cards); !reflect.DeepEqual(got, tt.cards) {
+				t.Errorf("Resolve() cards = %v, want %v", got, tt.cards)
+			}
+			if got := totals(res.Deck.Sideboard); !reflect.DeepEqual(got, tt.sideboard) {
+				t.Errorf("Resolve() sideboard = %v, want %v", got, tt.sideboard)
+			}
+
+			unmatched := make(map[int]error)
+			for _, u := range res.Unmatched {
+				unmatched[u.Line.Number] = u.Err
+			}
+			if len(unmatched) != len(tt.unmatched) {
+				t.Fatalf("Resolve() unmatched = %v, want %v", res.Unmatched, tt.unmatched)
+			}
+			for n, want := range tt.unmatched {
+				if !errors.Is(unmatched[n], want) {
+					t.Errorf("Resolve() line %d error = %v, want %v", n, unmatched[n], want)
+				}
+			}
+		})
+	}
+}
+
+// countingCards counts the lookups sent to the cards
+type countingCards struct {
+	fakeCards
+	gets, lists int
+}
+
+func (c *countingCards) GetCard(ctx context.Context, identifier string) (fabdb.Card, error) {
+	c.gets++
+	return c.fakeCards.GetCard(ctx, identifier)
+}
+
+func (c *countingCards) ListCards(ctx context.Context, query string) ([]fabdb.Card, error) {
+	c.lists++
+	return c.fakeCards.ListCards(ctx, query)
+}
+
+func TestResolveLookups(t *testing.T) {
+	cards := fakeCards{
+		{Identifier: "snatch-red", Name: "Snatch", Pitch: fabdb.NewStat(1)},
+		{Identifier: "snatch-blue", Name: "Snatch", Pitch: fabdb.NewStat(3)},
+	}
+
+	tests := []struct {
+		name        string
+		text        string
+		gets, lists int
+	}{
+		{name: "identifier with pitch", text: "1x Snatch (Blue)", gets: 1, lists: 0},
+		{name: "unknown card with pitch", text: "1x Sink Below (Red)", gets: 1, lists: 1},
+		{name: "card without pitch", text: "1x Snatch", gets: 1, lists: 1},
+		{name: "same line twice", text: "1x Snatch (Red)\n1x Snatch (Red)", gets: 1, lists: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &countingCards{fakeCards: cards}
+			if _, err := Resolve(context.Background(), c, Parse(tt.text)); err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if c.gets != tt.gets || c.lists != tt.lists {
+				t.Errorf("Resolve() sent %d identifier and %d name lookups, want %d and %d", c.gets, c.lists, tt.gets, tt.lists)
+			}
+		})
+	}
+}
+
+func totals(cards []fabdb.DeckCard) map[string]int {
+	if len(cards) == 0 {
+		return nil
+	}
+	m := make(map[string]int)
+	for _, c := range cards {
+		m[c.Card.Identifier] = c.Total
+	}
+	return m
+}
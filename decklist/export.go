@@ -0,0 +1,290 @@
+package decklist
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ExportFormat is a format decks can be exported to
+type ExportFormat string
+
+const (
+	// ExportText is the plain text decklist format read by Parse
+	ExportText ExportFormat = "text"
+	// ExportCSV is a spreadsheet with one row per card
+	ExportCSV ExportFormat = "csv"
+	// ExportJSON is the deck as returned by the fabdb.net API
+	ExportJSON ExportFormat = "json"
+	// ExportFabrary is the text format imported by fabrary.net
+	ExportFabrary ExportFormat = "fabrary"
+)
+
+// ExportFormats are all supported export formats
+var ExportFormats = []ExportFormat{ExportText, ExportCSV, ExportJSON, ExportFabrary}
+
+// ErrUnknownExportFormat is returned for unsupported export formats
+var ErrUnknownExportFormat = errors.New("unknown export format")
+
+// pitchNames are the names of the pitch values used in decklists
+var pitchNames = map[int]string{
+	fabdb.PitchRed:    "Red",
+	fabdb.PitchYellow: "Yellow",
+	fabdb.PitchBlue:   "Blue",
+}
+
+// csvHeader are the columns of a CSV export
+var csvHeader = []string{"section", "count", "name", "pitch", "identifier", "type", "cost", "power", "defense"}
+
+// ParseExportFormat returns the export format of the given name, e.g. "csv"
+func ParseExportFormat(name string) (ExportFormat, error) {
+	for _, f := range ExportFormats {
+		if strings.EqualFold(name, string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownExportFormat, name)
+}
+
+// Export writes the deck in the given format to w
+func Export(w io.Writer, deck fabdb.Deck, format ExportFormat) error {
+	switch format {
+	case ExportText:
+		return WriteText(w, deck)
+	case ExportCSV:
+		return WriteCSV(w, deck)
+	case ExportJSON:
+		return WriteJSON(w, deck)
+	case ExportFabrary:
+		return WriteFabrary(w, deck)
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownExportFormat, format)
+	}
+}
+
+// WriteText writes the deck as plain text decklist, weapons and equipment
+// listed in headers and the other cards one per line.
+func WriteText(w io.Writer, deck fabdb.Deck) error {
+	var sb strings.Builder
+	if deck.Name != "" {
+		fmt.Fprintf(&sb, "Name: %s\n", deck.Name)
+	}
+	if deck.Format != "" {
+		fmt.Fprintf(&sb, "Format: %s\n", FormatName(deck.Format))
+	}
+	if deck.Hero.Name != "" {
+		fmt.Fprintf(&sb, "Hero: %s\n", deck.Hero.Name)
+	}
+
+	arena, cards := splitArena(deck.Cards)
+	var weapons, equipment []string
+	for _, c := range arena {
+		name := c.Card.Name
+		if c.Total > 1 {
+			name = fmt.Sprintf("%dx %s", c.Total, name)
+		}
+		if c.Card.Type == "weapon" {
+			weapons = append(weapons, name)
+		} else {
+			equipment = append(equipment, name)
+		}
+	}
+	if len(weapons) > 0 {
+		fmt.Fprintf(&sb, "Weapons: %s\n", strings.Join(weapons, ", "))
+	}
+	if len(equipment) > 0 {
+		fmt.Fprintf(&sb, "Equipment: %s\n", strings.Join(equipment, ", "))
+	}
+
+	sb.WriteString("\n")
+	for _, c := range cards {
+		fmt.Fprintf(&sb, "%dx %s\n", c.Total, cardName(c.Card, false))
+	}
+
+	if len(deck.Sideboard) > 0 {
+		sb.WriteString("\nSideboard:\n")
+		for _, c := range deck.Sideboard {
+			fmt.Fprintf(&sb, "%dx %s\n", c.Total, cardName(c.Card, false))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteCSV writes the deck as CSV with a header and one row per card
+func WriteCSV(w io.Writer, deck fabdb.Deck) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	row := func(section Section, c fabdb.DeckCard) []string {
+		return []string{
+			section.String(),
+			strconv.Itoa(c.Total),
+			c.Card.Name,
+			c.Card.Pitch.String(),
+			c.Card.Identifier,
+			c.Card.Type,
+			c.Card.Cost.String(),
+			c.Card.Power.String(),
+			c.Card.Defense.String(),
+		}
+	}
+
+	if deck.Hero.Identifier != "" {
+		if err := cw.Write(row(SectionHero, fabdb.DeckCard{Card: deck.Hero, Total: 1})); err != nil {
+			return err
+		}
+	}
+	for _, c := range deck.Cards {
+		if err := cw.Write(row(SectionMain, c)); err != nil {
+			return err
+		}
+	}
+	for _, c := range deck.Sideboard {
+		if err := cw.Write(row(SectionSideboard, c)); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// jsonDeck is a deck in the schema of the fabdb.net API, which lists the
+// main deck and sideboard together in cards
+type jsonDeck struct {
+	Slug   string     `json:"slug"`
+	Name   string     `json:"name"`
+	Format string     `json:"format"`
+	Hero   fabdb.Card `json:"hero"`
+	Cards  []jsonCard `json:"cards"`
+}
+
+// jsonCard is a card of a deck in the schema of the fabdb.net API, with the
+// copies in the main deck as total and in the sideboard as sideboardTotal
+type jsonCard struct {
+	Card           fabdb.Card
+	Total          int
+	SideboardTotal int
+}
+
+// MarshalJSON encodes the card with additional total and sideboardTotal fields
+func (c jsonCard) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(c.Card)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["total"] = json.RawMessage(strconv.Itoa(c.Total))
+	fields["sideboardTotal"] = json.RawMessage(strconv.Itoa(c.SideboardTotal))
+	return json.Marshal(fields)
+}
+
+// WriteJSON writes the deck in the JSON format of the fabdb.net API. Cards
+// of the main deck and the sideboard are listed once in cards, with their
+// copies in the main deck as total and in the sideboard as sideboardTotal.
+func WriteJSON(w io.Writer, deck fabdb.Deck) error {
+	out := jsonDeck{
+		Slug:   deck.Slug,
+		Name:   deck.Name,
+		Format: deck.Format,
+		Hero:   deck.Hero,
+		Cards:  []jsonCard{},
+	}
+
+	index := make(map[string]int)
+	for _, c := range deck.Cards {
+		index[c.Card.Identifier] = len(out.Cards)
+		out.Cards = append(out.Cards, jsonCard{Card: c.Card, Total: c.Total})
+	}
+	for _, c := range deck.Sideboard {
+		if i, ok := index[c.Card.Identifier]; ok {
+			out.Cards[i].SideboardTotal += c.Total
+			continue
+		}
+		index[c.Card.Identifier] = len(out.Cards)
+		out.Cards = append(out.Cards, jsonCard{Card: c.Card, SideboardTotal: c.Total})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// WriteFabrary writes the deck in the text format imported by fabrary.net
+func WriteFabrary(w io.Writer, deck fabdb.Deck) error {
+	var sb strings.Builder
+	if deck.Name != "" {
+		fmt.Fprintf(&sb, "Name: %s\n", deck.Name)
+	}
+	if deck.Hero.Name != "" {
+		fmt.Fprintf(&sb, "Hero: %s\n", deck.Hero.Name)
+	}
+	if deck.Format != "" {
+		fmt.Fprintf(&sb, "Format: %s\n", FormatName(deck.Format))
+	}
+
+	arena, cards := splitArena(deck.Cards)
+	if len(arena) > 0 {
+		sb.WriteString("\nArena cards\n")
+		for _, c := range arena {
+			fmt.Fprintf(&sb, "%dx %s\n", c.Total, cardName(c.Card, true))
+		}
+	}
+	if len(cards) > 0 {
+		sb.WriteString("\nDeck cards\n")
+		for _, c := range cards {
+			fmt.Fprintf(&sb, "%dx %s\n", c.Total, cardName(c.Card, true))
+		}
+	}
+	if len(deck.Sideboard) > 0 {
+		sb.WriteString("\nSideboard\n")
+		for _, c := range deck.Sideboard {
+			fmt.Fprintf(&sb, "%dx %s\n", c.Total, cardName(c.Card, true))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// splitArena splits cards into weapons and equipment, which start the game
+// in the arena, and the cards shuffled into the deck.
+func splitArena(cards []fabdb.DeckCard) (arena, deck []fabdb.DeckCard) {
+	for _, c := range cards {
+		if c.Card.IsArenaCard() {
+			arena = append(arena, c)
+		} else {
+			deck = append(deck, c)
+		}
+	}
+	return arena, deck
+}
+
+// CardName returns the name of a card followed by its pitch, e.g. "Sink Below (Red)"
+func CardName(card fabdb.Card) string {
+	return cardName(card, false)
+}
+
+func cardName(card fabdb.Card, lower bool) string {
+	pitch, ok := pitchNames[card.Pitch.Value]
+	if !card.Pitch.Valid || !ok {
+		return card.Name
+	}
+	if lower {
+		pitch = strings.ToLower(pitch)
+	}
+	return fmt.Sprintf("%s (%s)", card.Name, pitch)
+}
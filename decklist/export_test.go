@@ -0,0 +1,88 @@
+package decklist
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"reflect"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	hero := fabdb.Card{Identifier: "dorinthea-ironsong", Name: "Dorinthea Ironsong", Type: "hero"}
+	snatch := fabdb.Card{Identifier: "snatch-red", Name: "Snatch", Pitch: fabdb.NewStat(1)}
+	sink := fabdb.Card{Identifier: "sink-below-red", Name: "Sink Below", Pitch: fabdb.NewStat(1)}
+
+	tests := []struct {
+		name  string
+		deck  fabdb.Deck
+		cards map[string][2]int
+	}{
+		{
+			name: "main deck only",
+			deck: fabdb.Deck{Slug: "abc", Name: "Dori", Hero: hero, Cards: []fabdb.DeckCard{{Card: snatch, Total: 3}}},
+			cards: map[string][2]int{
+				"snatch-red": {3, 0},
+			},
+		},
+		{
+			name: "card in main deck and sideboard",
+			deck: fabdb.Deck{
+				Slug:      "abc",
+				Name:      "Dori",
+				Hero:      hero,
+				Cards:     []fabdb.DeckCard{{Card: snatch, Total: 2}},
+				Sideboard: []fabdb.DeckCard{{Card: snatch, Total: 1}, {Card: sink, Total: 3}},
+			},
+			cards: map[string][2]int{
+				"snatch-red":     {2, 1},
+				"sink-below-red": {0, 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteJSON(&buf, tt.deck); err != nil {
+				t.Fatalf("WriteJSON() error = %v", err)
+			}
+
+			var out struct {
+				Slug string `json:"slug"`
+				Name string `json:"name"`
+				Hero struct {
+					Identifier string `json:"identifier"`
+				} `json:"hero"`
+				Cards []struct {
+					Identifier     string `json:"identifier"`
+					Total          int    `json:"total"`
+					SideboardTotal int    `json:"sideboardTotal"`
+				} `json:"cards"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+				t.Fatalf("WriteJSON() wrote invalid JSON: %v", err)
+			}
+			if out.Slug != tt.deck.Slug || out.Name != tt.deck.Name || out.Hero.Identifier != tt.deck.Hero.Identifier {
+				t.Errorf("WriteJSON() = %q, %q, hero %q, want %q, %q, hero %q",
+					out.Slug, out.Name, out.Hero.Identifier, tt.deck.Slug, tt.deck.Name, tt.deck.Hero.Identifier)
+			}
+			cards := make(map[string][2]int)
+			for _, c := range out.Cards {
+				cards[c.Identifier] = [2]int{c.Total, c.SideboardTotal}
+			}
+			if !reflect.DeepEqual(cards, tt.cards) {
+				t.Errorf("WriteJSON() cards = %v, want %v", cards, tt.cards)
+			}
+
+			var deck fabdb.Deck
+			if err := json.Unmarshal(buf.Bytes(), &deck); err != nil {
+				t.Fatalf("fabdb.Deck can't decode WriteJSON(): %v", err)
+			}
+			if deck.Count() != tt.deck.Count() || deck.SideboardCount() != tt.deck.SideboardCount() {
+				t.Errorf("decoded deck has %d cards and %d sideboard cards, want %d and %d",
+					deck.Count(), deck.SideboardCount(), tt.deck.Count(), tt.deck.SideboardCount())
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package decklist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ErrInvalidLine is reported for lines that are neither a card nor a header
+	ErrInvalidLine = errors.New("not a card line")
+	// ErrNoMatch is reported for lines without a card of the given name
+	ErrNoMatch = errors.New("no matching card")
+	// ErrAmbiguous is reported for lines matching several cards, e.g. if the pitch is missing
+	ErrAmbiguous = errors.New("several matching cards, please add the pitch")
+	// ErrSecondHero is reported for hero lines after the first
+	ErrSecondHero = errors.New("the deck has a hero already")
+)
+
+// Cards looks up cards by identifier or name. It is implemented by all card sources of the bot.
+type Cards interface {
+	ListCards(ctx context.Context, query string) ([]fabdb.Card, error)
+	GetCard(ctx context.Context, identifier string) (fabdb.Card, error)
+}
+
+// Unmatched is a line of a decklist that could not be resolved to a card
+type Unmatched struct {
+	Line Line
+	Err  error
+}
+
+func (u Unmatched) String() string {
+	return fmt.Sprintf("line %d: %s (%v)", u.Line.Number, u.Line.Text, u.Err)
+}
+
+// Result is a decklist resolved to cards
+type Result struct {
+	Deck      fabdb.Deck
+	Unmatched []Unmatched
+}
+
+// Resolve looks up the cards of every line of the decklist. Lines without
+// a unique match are reported in the result, an error is only returned if
+// the cards could not be looked up at all. Cards are looked up by their
+// fabdb.net identifier derived from the name, like "snatch-red", and only
+// searched by name if there is no such card.
+func Resolve(ctx context.Context, cards Cards, list Decklist) (Result, error) {
+	result := Result{
+		Deck: fabdb.Deck{
+			Name:   list.Name,
+			Format: list.Format,
+		},
+	}
+	for _, line := range list.Invalid {
+		result.Unmatched = append(result.Unmatched, Unmatched{Line: line, Err: ErrInvalidLine})
+	}
+
+	found := make(map[string][]fabdb.Card)
+	for _, line := range list.Lines {
+		key := normalizeName(line.Name) + "|" + strconv.Itoa(line.Pitch)
+		candidates, ok := found[key]
+		if !ok {
+			var err error
+			candidates, err = lookup(ctx, cards, line)
+			if err != nil {
+				return Result{}, err
+			}
+			found[key] = candidates
+		}
+
+		card, err := match(candidates, line)
+		if err != nil {
+			result.Unmatched = append(result.Unmatched, Unmatched{Line: line, Err: err})
+			continue
+		}
+
+		switch line.Section {
+		case SectionHero:
+			if result.Deck.Hero.Identifier != "" {
+				result.Unmatched = append(result.Unmatched, Unmatched{Line: line, Err: ErrSecondHero})
+				continue
+			}
+			result.Deck.Hero = card
+		case SectionSideboard:
+			result.Deck.Sideboard = add(result.Deck.Sideboard, card, line.Count)
+		default:
+			result.Deck.Cards = add(result.Deck.Cards, card, line.Count)
+		}
+	}
+
+	sort.SliceStable(result.Unmatched, func(i, j int) bool {
+		return result.Unmatched[i].Line.Number < result.Unmatched[j].Line.Number
+	})
+	return result, nil
+}
+
+// lookup returns the cards that may be meant by the line, looked up by
+// identifier or, if there is none, searched by name. Lines with pitch are
+// looked up by the identifier of that pitch, like "snatch-red", lines
+// without by the identifier without pitch, like "helm-of-isens-peak". Cards
+// of several pitches are only found by name then, so that missing pitches
+// are reported as ambiguous.
+func lookup(ctx context.Context, cards Cards, line Line) ([]fabdb.Card, error) {
+	id := identifier(line.Name)
+	if line.Pitch > 0 && line.Pitch < len(identifierColors) {
+		id += "-" + identifierColors[line.Pitch]
+	}
+
+	card, err := cards.GetCard(ctx, id)
+	switch {
+	case err == nil:
+		return []fabdb.Card{card}, nil
+	case !isNotFound(err):
+		return nil, err
+	}
+
+	candidates, err := cards.ListCards(ctx, normalizeName(line.Name))
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// identifierColors are the suffixes of the identifiers of cards with a pitch, in order of pitch
+var identifierColors = []string{fabdb.PitchRed: "red", fabdb.PitchYellow: "yellow", fabdb.PitchBlue: "blue"}
+
+// identifier returns the fabdb.net identifier of a card name without pitch,
+// e.g. "helm-of-isens-peak" for "Helm of Isen's Peak"
+func identifier(name string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range normalizeName(name) {
+		switch {
+		case r == '\'':
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && sb.Len() > 0 {
+				sb.WriteRune('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	return sb.String()
+}
+
+// match returns the card named like the line, of the line's pitch if given
+func match(candidates []fabdb.Card, line Line) (fabdb.Card, error) {
+	name := normalizeName(line.Name)
+
+	var matches []fabdb.Card
+	seen := make(map[string]bool)
+	for _, card := range candidates {
+		if normalizeName(card.Name) != name || seen[card.Identifier] {
+			continue
+		}
+		if line.Pitch != 0 && !card.IsPitch(line.Pitch) {
+			continue
+		}
+		seen[card.Identifier] = true
+		matches = append(matches, card)
+	}
+
+	switch len(matches) {
+	case 0:
+		return fabdb.Card{}, ErrNoMatch
+	case 1:
+		return matches[0], nil
+	default:
+		return fabdb.Card{}, ErrAmbiguous
+	}
+}
+
+// add adds copies of a card to a list of deck cards, merging lines of the same card
+func add(cards []fabdb.DeckCard, card fabdb.Card, count int) []fabdb.DeckCard {
+	for i := range cards {
+		if cards[i].Card.Identifier == card.Identifier {
+			cards[i].Total += count
+			return cards
+		}
+	}
+	return append(cards, fabdb.DeckCard{Card: card, Total: count})
+}
+
+// normalizeName returns a canonical form of a card name for comparison
+func normalizeName(name string) string {
+	name = strings.NewReplacer("’", "'", "`", "'").Replace(name)
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+func isNotFound(err error) bool {
+	var notFound fabdb.NotFoundError
+	return errors.Is(err, fabdb.ErrNoCards) || errors.As(err, &notFound)
+}
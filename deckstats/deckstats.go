@@ -0,0 +1,149 @@
+// Package deckstats analyzes the composition of a deck, like its pitch
+// distribution and cost curve.
+package deckstats
+
+import (
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"sort"
+)
+
+// Stats describes the cards of a deck. Weapons and equipment are only
+// counted in Arena, all other numbers are about the cards in the deck.
+type Stats struct {
+	// Cards is the number of cards in the deck
+	Cards int
+	// Arena is the number of weapons and equipment
+	Arena int
+
+	// Pitch is the number of cards per pitch value, 0 for cards without pitch
+	Pitch map[int]int
+	// Resources is the total pitch value of all cards
+	Resources int
+
+	// Costs is the number of cards per cost, cards with variable cost are counted in VariableCost
+	Costs        map[int]int
+	VariableCost int
+
+	// Attacks is the number of cards with power
+	Attacks        int
+	AveragePower   float64
+	AverageDefense float64
+
+	// Types is the number of cards per group, see Group
+	Types map[string]int
+	// Classes is the number of cards per class
+	Classes map[string]int
+}
+
+// Analyze computes the stats of the main deck cards
+func Analyze(cards []fabdb.DeckCard) Stats {
+	s := Stats{
+		Pitch:   make(map[int]int),
+		Costs:   make(map[int]int),
+		Types:   make(map[string]int),
+		Classes: make(map[string]int),
+	}
+
+	var power, defense, defenders int
+	for _, c := range cards {
+		card, n := c.Card, c.Total
+		if card.IsArenaCard() {
+			s.Arena += n
+			continue
+		}
+		s.Cards += n
+
+		if card.Pitch.Valid {
+			s.Pitch[card.Pitch.Value] += n
+			s.Resources += card.Pitch.Value * n
+		} else {
+			s.Pitch[0] += n
+		}
+
+		if card.Cost.Valid {
+			s.Costs[card.Cost.Value] += n
+		} else {
+			s.VariableCost += n
+		}
+
+		if card.Power.Valid {
+			s.Attacks += n
+			power += card.Power.Value * n
+		}
+		if card.Defense.Valid {
+			defenders += n
+			defense += card.Defense.Value * n
+		}
+
+		s.Types[Group(card)] += n
+		class := card.Class
+		if class == "" {
+			class = "generic"
+		}
+		s.Classes[class] += n
+	}
+
+	if s.Attacks > 0 {
+		s.AveragePower = float64(power) / float64(s.Attacks)
+	}
+	if defenders > 0 {
+		s.AverageDefense = float64(defense) / float64(defenders)
+	}
+	return s
+}
+
+// AverageResources returns the average pitch value per card
+func (s Stats) AverageResources() float64 {
+	if s.Cards == 0 {
+		return 0
+	}
+	return float64(s.Resources) / float64(s.Cards)
+}
+
+// MaxCost returns the highest cost of any card in the deck
+func (s Stats) MaxCost() int {
+	max := 0
+	for cost := range s.Costs {
+		if cost > max {
+			max = cost
+		}
+	}
+	return max
+}
+
+// Count is the number of cards of a group, class or other key
+type Count struct {
+	Name  string
+	Count int
+}
+
+// Sorted returns the counts sorted by number of cards, most first
+func Sorted(counts map[string]int) []Count {
+	res := make([]Count, 0, len(counts))
+	for name, n := range counts {
+		res = append(res, Count{Name: name, Count: n})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// Group returns the name a card is grouped by in deck listings, which is
+// its type and "Attack Action" for attack actions, e.g. "Defense Reaction".
+func Group(card fabdb.Card) string {
+	if card.Type == "" {
+		return "Other"
+	}
+	if card.Type == "action" {
+		for _, s := range card.Subtypes {
+			if s == "attack" {
+				return "Attack Action"
+			}
+		}
+	}
+	return fabdb.Title(card.Type)
+}
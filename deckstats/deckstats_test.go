@@ -0,0 +1,120 @@
+package deckstats
+
+import (
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"reflect"
+	"testing"
+)
+
+func attack(class string, cost, pitch, power, defense, copies int) fabdb.DeckCard {
+	return fabdb.DeckCard{
+		Card: fabdb.Card{
+			Class:    class,
+			Type:     "action",
+			Subtypes: []string{"attack"},
+			Cost:     fabdb.NewStat(cost),
+			Pitch:    fabdb.NewStat(pitch),
+			Power:    fabdb.NewStat(power),
+			Defense:  fabdb.NewStat(defense),
+		},
+		Total: copies,
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name  string
+		cards []fabdb.DeckCard
+		want  Stats
+	}{
+		{
+			name: "empty deck",
+			want: Stats{Pitch: map[int]int{}, Costs: map[int]int{}, Types: map[string]int{}, Classes: map[string]int{}},
+		},
+		{
+			name: "mixed deck",
+			cards: []fabdb.DeckCard{
+				attack("warrior", 0, 1, 3, 2, 3),
+				attack("", 2, 3, 5, 3, 1),
+				{Card: fabdb.Card{Type: "defense reaction", Class: "warrior", Cost: fabdb.NewStat(0), Pitch: fabdb.NewStat(2), Defense: fabdb.NewStat(4)}, Total: 2},
+				{Card: fabdb.Card{Type: "instant", Pitch: fabdb.NewStat(1)}, Total: 2},
+				{Card: fabdb.Card{Type: "weapon", Class: "warrior"}, Total: 1},
+				{Card: fabdb.Card{Type: "equipment", Defense: fabdb.NewStat(1)}, Total: 2},
+			},
+			want: Stats{
+				Cards:          8,
+				Arena:          3,
+				Pitch:          map[int]int{1: 5, 2: 2, 3: 1},
+				Resources:      12,
+				Costs:          map[int]int{0: 5, 2: 1},
+				VariableCost:   2,
+				Attacks:        4,
+				AveragePower:   3.5,
+				AverageDefense: 17.0 / 6,
+				Types:          map[string]int{"Attack Action": 4, "Defense Reaction": 2, "Instant": 2},
+				Classes:        map[string]int{"warrior": 5, "generic": 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Analyze(tt.cards); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Analyze() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsAverageResources(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats Stats
+		want  float64
+	}{
+		{name: "no cards", stats: Stats{}, want: 0},
+		{name: "cards", stats: Stats{Cards: 4, Resources: 10}, want: 2.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.AverageResources(); got != tt.want {
+				t.Errorf("AverageResources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsMaxCost(t *testing.T) {
+	if got := (Stats{Costs: map[int]int{0: 3, 4: 1, 2: 5}}).MaxCost(); got != 4 {
+		t.Errorf("MaxCost() = %d, want 4", got)
+	}
+}
+
+func TestSorted(t *testing.T) {
+	got := Sorted(map[string]int{"Instant": 2, "Attack Action": 10, "Defense Reaction": 2})
+	want := []Count{{Name: "Attack Action", Count: 10}, {Name: "Defense Reaction", Count: 2}, {Name: "Instant", Count: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sorted() = %v, want %v", got, want)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	tests := []struct {
+		card fabdb.Card
+		want string
+	}{
+		{card: fabdb.Card{Type: "action", Subtypes: []string{"attack"}}, want: "Attack Action"},
+		{card: fabdb.Card{Type: "action", Subtypes: []string{"aura"}}, want: "Action"},
+		{card: fabdb.Card{Type: "defense reaction"}, want: "Defense Reaction"},
+		{card: fabdb.Card{}, want: "Other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Group(tt.card); got != tt.want {
+				t.Errorf("Group() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package fabdb
+
+import "io"
+
+// defaultMaxResponseSize is the default limit for decoded response bodies
+const defaultMaxResponseSize = 16 << 20
+
+// limitedReader reads from r until the limit has been reached, failing with
+// ErrResponseTooLarge afterwards. It remembers the first error returned.
+type limitedReader struct {
+	r         io.Reader
+	limited   bool
+	remaining int64
+	err       error
+}
+
+// newLimitedReader returns a reader limited to n bytes, zero or negative values disable the limit
+func newLimitedReader(r io.Reader, n int64) *limitedReader {
+	return &limitedReader{r: r, limited: n > 0, remaining: n}
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+
+	// read one byte beyond the limit to tell apart bodies of exactly the maximum size
+	if l.limited && int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+
+	n, err := l.r.Read(p)
+	if l.limited {
+		l.remaining -= int64(n)
+		if l.remaining < 0 {
+			n += int(l.remaining)
+			err = ErrResponseTooLarge
+		}
+	}
+
+	l.err = err
+	return n, err
+}
@@ -0,0 +1,68 @@
+package fabdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClientMaxResponseSize(t *testing.T) {
+	body := `{"identifier":"snatch-red","text":"Take it"}`
+
+	tests := []struct {
+		name    string
+		max     int64
+		chunked bool
+		tooBig  bool
+	}{
+		{name: "below limit", max: int64(len(body)) + 1},
+		{name: "exactly at limit", max: int64(len(body))},
+		{name: "no limit", max: 0},
+		{name: "content length above limit", max: 10, tooBig: true},
+		{name: "streamed body above limit", max: 10, chunked: true, tooBig: true},
+		{name: "streamed body at limit", max: int64(len(body)), chunked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if !tt.chunked {
+					w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+				}
+				for _, part := range strings.SplitAfter(body, ",") {
+					_, _ = w.Write([]byte(part))
+					w.(http.Flusher).Flush()
+				}
+			}, WithMaxResponseSize(tt.max))
+
+			card, err := client.GetCard(context.Background(), "snatch-red")
+			if !tt.tooBig {
+				if err != nil || card.Identifier != "snatch-red" {
+					t.Errorf("GetCard() = %q, %v, want snatch-red", card.Identifier, err)
+				}
+				return
+			}
+
+			var decodeErr DecodeError
+			if !errors.As(err, &decodeErr) || !errors.Is(err, ErrResponseTooLarge) {
+				t.Errorf("GetCard() error = %v (%T), want DecodeError with ErrResponseTooLarge", err, err)
+			}
+		})
+	}
+}
+
+func TestClientDecodeError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, `{"identifier":`)
+	})
+
+	_, err := client.GetCard(context.Background(), "snatch-red")
+	var decodeErr DecodeError
+	if !errors.As(err, &decodeErr) || errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("GetCard() error = %v (%T), want DecodeError", err, err)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net"
 	"net/http"
 	"runtime"
@@ -21,19 +20,6 @@ const (
 	apiEndpoint = "https://api.fabdb.net"
 )
 
-type APIError struct {
-	// StatusCode is the HTTP response status code
-	StatusCode int `json:"-"`
-	message    string
-}
-
-func (a APIError) Error() string {
-	if len(a.message) > 0 {
-		return a.message
-	}
-	return fmt.Sprintf("HTTP Requests failed with statuscode %d", a.StatusCode)
-}
-
 func newDefaultHTTPClient() *http.Client {
 	return &http.Client{
 		Transport: &http.Transport{
@@ -65,6 +51,13 @@ type Client struct {
 	// FabDB.net API. You can use either *http.Client here, or your own
 	// implementation.
 	HTTPClient HTTPClient
+
+	retry      RetryPolicy
+	limiter    *rateLimiter
+	validators *validatorCache
+
+	maxResponseSize int64
+	driftHandler    DriftHandler
 }
 
 // ClientOptions allows for options to be passed into the Client for customization
@@ -75,6 +68,9 @@ func NewClient(options ...ClientOptions) *Client {
 	client := Client{
 		apiEndpoint: apiEndpoint,
 		HTTPClient:  defaultHTTPClient,
+		retry:       DefaultRetryPolicy(),
+
+		maxResponseSize: defaultMaxResponseSize,
 	}
 
 	for _, opt := range options {
@@ -91,6 +87,14 @@ func WithAPIEndpoint(endpoint string) ClientOptions {
 	}
 }
 
+// WithMaxResponseSize limits the size of response bodies decoded by the client.
+// Zero or negative values disable the limit.
+func WithMaxResponseSize(n int64) ClientOptions {
+	return func(c *Client) {
+		c.maxResponseSize = n
+	}
+}
+
 // Do sets some headers on the request, before actioning it using the internal
 // HTTPClient. This also assumes any request body is in JSON format and sets the Content-Type to application/json.
 func (c *Client) Do(r *http.Request) (*http.Response, error) {
@@ -145,22 +149,49 @@ func (c *Client) do(ctx context.Context, method, path string, body io.Reader, he
 }
 
 func (c *Client) doWithEndpoint(ctx context.Context, endpoint, method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
-	var resp *http.Response
-	req, err := http.NewRequestWithContext(ctx, method, endpoint+path, body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build request: %w", err)
+	attempts := c.retry.MaxAttempts
+	if body != nil || !isIdempotent(method) || attempts < 1 {
+		attempts = 1
 	}
 
-	c.prepRequest(req, headers)
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, endpoint+path, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request: %w", err)
+		}
+
+		c.prepRequest(req, headers)
+		c.validators.prepare(req)
+
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		resp, err = c.checkResponse(c.validators.update(req, resp), err)
+		if err == nil || attempt >= attempts || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
 
-	resp, err = c.HTTPClient.Do(req)
+		delay := c.retry.backoff(attempt)
+		if d, ok := retryAfter(resp); ok {
+			// never retry before the server allows it, give up if that is too far off
+			if c.retry.MaxDelay > 0 && d > c.retry.MaxDelay {
+				return resp, err
+			}
+			delay = d
+		}
 
-	return c.checkResponse(resp, err)
+		discard(resp)
+		if !wait(ctx, delay) {
+			return nil, err
+		}
+	}
 }
 
 func (c *Client) checkResponse(resp *http.Response, err error) (*http.Response, error) {
 	if err != nil {
-		return resp, fmt.Errorf("Error calling the API endpoint: %v", err)
+		return resp, TransportError{Err: err}
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
@@ -170,25 +201,50 @@ func (c *Client) checkResponse(resp *http.Response, err error) (*http.Response,
 	return resp, nil
 }
 
+// decodeJSON decodes the response body into payload while reading it and
+// closes the body afterwards. Bodies exceeding the maximum response size of
+// the client fail with ErrResponseTooLarge.
 func (c *Client) decodeJSON(resp *http.Response, payload interface{}) error {
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
+	defer resp.Body.Close()
 
-	return json.Unmarshal(body, payload)
-}
+	if c.maxResponseSize > 0 && resp.ContentLength > c.maxResponseSize {
+		return DecodeError{Err: ErrResponseTooLarge}
+	}
 
-func (c *Client) getErrorFromResponse(resp *http.Response) APIError {
-	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
-		apierror := APIError{
-			StatusCode: resp.StatusCode,
-			message:    fmt.Sprintf("HTTP response with status code %d does not contain Content-Type: application/json", resp.StatusCode),
+	body := newLimitedReader(resp.Body, c.maxResponseSize)
+	if err := json.NewDecoder(body).Decode(payload); err != nil {
+		if body.err != nil && body.err != io.EOF && body.err != ErrResponseTooLarge {
+			return TransportError{Err: fmt.Errorf("failed to read response body: %w", body.err)}
 		}
-		return apierror
+		return DecodeError{Err: err}
 	}
-	return APIError{
+	return nil
+}
+
+func (c *Client) getErrorFromResponse(resp *http.Response) error {
+	defer resp.Body.Close()
+
+	apiErr := APIError{
 		StatusCode: resp.StatusCode,
 		message:    fmt.Sprintf("HTTP response with status code %d", resp.StatusCode),
 	}
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		apiErr.message = fmt.Sprintf("HTTP response with status code %d does not contain Content-Type: application/json", resp.StatusCode)
+	} else {
+		// the body is informational only, ignore it if it can't be decoded
+		_ = json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&apiErr)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return NotFoundError{APIError: apiErr}
+	case resp.StatusCode == http.StatusTooManyRequests:
+		wait, _ := retryAfter(resp)
+		return RateLimitedError{APIError: apiErr, RetryAfter: wait}
+	case resp.StatusCode >= 500:
+		return ServerError{APIError: apiErr}
+	default:
+		return apiErr
+	}
 }
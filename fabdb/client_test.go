@@ -0,0 +1,29 @@
+package fabdb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// testRetryPolicy retries quickly, so that tests don't wait for the backoff
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+// newTestClient returns a client sending its requests to a test server serving h
+func newTestClient(t *testing.T, h http.HandlerFunc, opts ...ClientOptions) *FabDBClient {
+	t.Helper()
+
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	opts = append([]ClientOptions{WithAPIEndpoint(srv.URL), WithRetryPolicy(testRetryPolicy)}, opts...)
+	return NewFabDBClient(WithClient(NewClient(opts...)))
+}
+
+// writeJSON writes body as JSON response with the given status code
+func writeJSON(w http.ResponseWriter, code int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write([]byte(body))
+}
@@ -0,0 +1,275 @@
+package fabdb
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+const (
+	// maxValidatedBodySize limits the size of each response body kept for conditional requests
+	maxValidatedBodySize = 1 << 20
+	// maxValidatedTotalSize limits the size of all response bodies kept for conditional requests
+	maxValidatedTotalSize = 64 << 20
+)
+
+// WithConditionalRequests keeps the validators and bodies of up to maxEntries
+// GET responses, bodies above 1 MiB or beyond 64 MiB in total are dropped.
+// Later requests for the same URL are sent with If-None-Match and
+// If-Modified-Since headers, a 304 Not Modified response is then served from
+// the stored body.
+func WithConditionalRequests(maxEntries int) ClientOptions {
+	return func(c *Client) {
+		if maxEntries > 0 {
+			c.validators = newValidatorCache(maxEntries)
+		}
+	}
+}
+
+// CachedResponse is a GET response kept for conditional requests together
+// with its validators. Responses can be exported from a client and restored
+// into another one, to revalidate them e.g. in the next snapshot pull.
+type CachedResponse struct {
+	URL          string          `json:"url"`
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"last_modified,omitempty"`
+	ContentType  string          `json:"content_type,omitempty"`
+	Body         json.RawMessage `json:"body"`
+}
+
+// CachedResponses returns the responses kept for conditional requests, least
+// recently used first. Only JSON bodies are returned, restored responses
+// only if they have been requested since. It returns nil if conditional
+// requests are disabled.
+func (c *Client) CachedResponses() []CachedResponse {
+	v := c.validators
+	if v == nil {
+		return nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	res := make([]CachedResponse, 0, v.lru.Len())
+	for el := v.lru.Back(); el != nil; el = el.Prev() {
+		r := el.Value.(*validatedResponse)
+		if r.restored || !json.Valid(r.body) {
+			continue
+		}
+		res = append(res, CachedResponse{
+			URL:          r.url,
+			ETag:         r.etag,
+			LastModified: r.lastModified,
+			ContentType:  r.header.Get("Content-Type"),
+			Body:         json.RawMessage(r.body),
+		})
+	}
+	return res
+}
+
+// RestoreCachedResponses adds previously exported responses to the responses
+// kept for conditional requests. It does nothing if they are disabled.
+func (c *Client) RestoreCachedResponses(responses []CachedResponse) {
+	if c.validators == nil {
+		return
+	}
+
+	for _, r := range responses {
+		if r.URL == "" || (r.ETag == "" && r.LastModified == "") || len(r.Body) > maxValidatedBodySize {
+			continue
+		}
+		header := http.Header{}
+		if r.ContentType != "" {
+			header.Set("Content-Type", r.ContentType)
+		}
+		c.validators.set(&validatedResponse{
+			url:          r.URL,
+			etag:         r.ETag,
+			lastModified: r.LastModified,
+			header:       header,
+			body:         append([]byte(nil), r.Body...),
+			restored:     true,
+		})
+	}
+}
+
+type validatorCache struct {
+	size     int
+	maxBytes int
+
+	mu    sync.Mutex
+	lru   *list.List
+	items map[string]*list.Element
+	bytes int
+}
+
+type validatedResponse struct {
+	url          string
+	etag         string
+	lastModified string
+	header       http.Header
+	body         []byte
+	// restored is true for restored responses until they are requested again
+	restored bool
+}
+
+func newValidatorCache(size int) *validatorCache {
+	return &validatorCache{
+		size:     size,
+		maxBytes: maxValidatedTotalSize,
+		lru:      list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (v *validatorCache) get(url string) (*validatedResponse, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	el, ok := v.items[url]
+	if !ok {
+		return nil, false
+	}
+	v.lru.MoveToFront(el)
+	r := el.Value.(*validatedResponse)
+	r.restored = false
+	return r, true
+}
+
+func (v *validatorCache) set(r *validatedResponse) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if el, ok := v.items[r.url]; ok {
+		v.bytes -= len(el.Value.(*validatedResponse).body)
+		el.Value = r
+		v.lru.MoveToFront(el)
+	} else {
+		v.items[r.url] = v.lru.PushFront(r)
+	}
+	v.bytes += len(r.body)
+
+	for v.lru.Len() > v.size || v.bytes > v.maxBytes {
+		el := v.lru.Back()
+		v.lru.Remove(el)
+		old := el.Value.(*validatedResponse)
+		delete(v.items, old.url)
+		v.bytes -= len(old.body)
+	}
+}
+
+// prepare adds the validators of a stored response to the request
+func (v *validatorCache) prepare(req *http.Request) {
+	if v == nil || req.Method != http.MethodGet {
+		return
+	}
+
+	r, ok := v.get(req.URL.String())
+	if !ok {
+		return
+	}
+	if r.etag != "" {
+		req.Header.Set("If-None-Match", r.etag)
+	}
+	if r.lastModified != "" {
+		req.Header.Set("If-Modified-Since", r.lastModified)
+	}
+}
+
+// update serves 304 Not Modified responses from the stored body and records
+// the body of successful responses carrying validators.
+func (v *validatorCache) update(req *http.Request, resp *http.Response) *http.Response {
+	if v == nil || resp == nil || req.Method != http.MethodGet {
+		return resp
+	}
+
+	url := req.URL.String()
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		r, ok := v.get(url)
+		if !ok {
+			return resp
+		}
+		discard(resp)
+
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        r.header.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader(r.body)),
+			ContentLength: int64(len(r.body)),
+			Request:       req,
+		}
+	case resp.StatusCode == http.StatusOK:
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		if etag == "" && lastModified == "" {
+			return resp
+		}
+
+		resp.Body = &recordingBody{
+			ReadCloser: resp.Body,
+			cache:      v,
+			response: &validatedResponse{
+				url:          url,
+				etag:         etag,
+				lastModified: lastModified,
+				header:       resp.Header.Clone(),
+			},
+		}
+	}
+	return resp
+}
+
+// recordingBody copies a response body while it is read and stores it in the
+// validator cache once it has been read completely.
+type recordingBody struct {
+	io.ReadCloser
+	cache    *validatorCache
+	response *validatedResponse
+	buf      bytes.Buffer
+	failed   bool
+	stored   bool
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && !b.failed {
+		if b.buf.Len()+n > maxValidatedBodySize {
+			b.failed = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF {
+		b.store()
+	} else if err != nil {
+		b.failed = true
+	}
+	return n, err
+}
+
+func (b *recordingBody) store() {
+	if b.failed || b.stored {
+		return
+	}
+	b.stored = true
+	b.response.body = b.buf.Bytes()
+	b.cache.set(b.response)
+}
+
+// Close reads the remainder of the body, e.g. trailing whitespace left by a
+// JSON decoder, and stores the complete body before closing it.
+func (b *recordingBody) Close() error {
+	if !b.failed && !b.stored {
+		_, _ = io.Copy(ioutil.Discard, io.LimitReader(b, maxValidatedBodySize+1))
+	}
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,116 @@
+package fabdb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClientConditionalRequests(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		etag         string
+		lastModified string
+		revalidated  bool
+	}{
+		{name: "etag", text: "Take it", etag: `"v1"`, revalidated: true},
+		{name: "last modified", text: "Take it", lastModified: "Wed, 21 Oct 2020 07:28:00 GMT", revalidated: true},
+		{name: "no validators", text: "Take it", revalidated: false},
+		{name: "body above limit", text: strings.Repeat("a", maxValidatedBodySize), etag: `"v1"`, revalidated: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests, notModified int
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if (tt.etag != "" && r.Header.Get("If-None-Match") == tt.etag) ||
+					(tt.lastModified != "" && r.Header.Get("If-Modified-Since") == tt.lastModified) {
+					notModified++
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				if tt.etag != "" {
+					w.Header().Set("ETag", tt.etag)
+				}
+				if tt.lastModified != "" {
+					w.Header().Set("Last-Modified", tt.lastModified)
+				}
+				writeJSON(w, http.StatusOK, fmt.Sprintf(`{"identifier":"snatch-red","text":%q}`, tt.text))
+			}, WithConditionalRequests(10))
+
+			for i := 0; i < 2; i++ {
+				card, err := client.GetCard(context.Background(), "snatch-red")
+				if err != nil {
+					t.Fatalf("GetCard() error = %v", err)
+				}
+				if card.Identifier != "snatch-red" || card.Text != tt.text {
+					t.Errorf("GetCard() = %q with %d bytes of text, want snatch-red with %d", card.Identifier, len(card.Text), len(tt.text))
+				}
+			}
+
+			if requests != 2 {
+				t.Errorf("GetCard() sent %d requests, want 2", requests)
+			}
+			if got := notModified == 1; got != tt.revalidated {
+				t.Errorf("GetCard() revalidated = %t, want %t", got, tt.revalidated)
+			}
+		})
+	}
+}
+
+func TestValidatorCacheEviction(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		maxBytes int
+		urls     []string
+		kept     []string
+	}{
+		{
+			name:     "entries",
+			size:     2,
+			maxBytes: 100,
+			urls:     []string{"a", "b", "c"},
+			kept:     []string{"b", "c"},
+		},
+		{
+			name:     "bytes",
+			size:     10,
+			maxBytes: 10,
+			urls:     []string{"a", "b", "c"},
+			kept:     []string{"b", "c"},
+		},
+		{
+			name:     "replaced entry",
+			size:     10,
+			maxBytes: 10,
+			urls:     []string{"a", "b", "a"},
+			kept:     []string{"b", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newValidatorCache(tt.size)
+			v.maxBytes = tt.maxBytes
+			for _, url := range tt.urls {
+				v.set(&validatedResponse{url: url, etag: `"v1"`, body: []byte("body")})
+			}
+
+			var kept []string
+			for el := v.lru.Back(); el != nil; el = el.Prev() {
+				kept = append(kept, el.Value.(*validatedResponse).url)
+			}
+			if strings.Join(kept, ",") != strings.Join(tt.kept, ",") {
+				t.Errorf("set() kept %v, want %v", kept, tt.kept)
+			}
+			if want := 4 * len(tt.kept); v.bytes != want {
+				t.Errorf("set() counted %d bytes, want %d", v.bytes, want)
+			}
+		})
+	}
+}
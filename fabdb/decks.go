@@ -0,0 +1,179 @@
+package fabdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Deck is a Flesh and Blood deck as published on fabdb.net
+type Deck struct {
+	Slug   string `json:"slug"`
+	Name   string `json:"name"`
+	Format string `json:"format"`
+	Hero   Card   `json:"hero"`
+
+	// Cards is the main deck, including weapons and equipment
+	Cards     []DeckCard `json:"cards"`
+	Sideboard []DeckCard `json:"sideboard"`
+
+	drift []SchemaDrift
+}
+
+// DeckCard is a card of a deck together with the number of copies
+type DeckCard struct {
+	Card  Card
+	Total int
+}
+
+// GetDeck returns the public deck with the given slug, e.g. "aBcDeFgH".
+// Deck URLs are accepted as well, see DeckSlug.
+func (c *FabDBClient) GetDeck(ctx context.Context, slug string) (Deck, error) {
+	resp, err := c.client.get(ctx, "/decks/"+url.PathEscape(DeckSlug(slug)))
+	if err != nil {
+		return Deck{}, err
+	}
+
+	var result Deck
+	if err := c.client.decodeJSON(resp, &result); err != nil {
+		return Deck{}, err
+	}
+	c.client.reportDrift(result.drift)
+
+	return result, nil
+}
+
+// DeckSlug returns the slug of a deck from a fabdb.net deck URL like
+// https://fabdb.net/decks/aBcDeFgH. Anything else is returned trimmed.
+func DeckSlug(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.Contains(s, "/") {
+		return s
+	}
+
+	if u, err := url.Parse(s); err == nil {
+		s = u.Path
+	}
+	segments := strings.Split(strings.Trim(s, "/"), "/")
+	return segments[len(segments)-1]
+}
+
+// Count returns the number of cards in the main deck
+func (d Deck) Count() int {
+	return countCards(d.Cards)
+}
+
+// SideboardCount returns the number of cards in the sideboard
+func (d Deck) SideboardCount() int {
+	return countCards(d.Sideboard)
+}
+
+func countCards(cards []DeckCard) int {
+	n := 0
+	for _, c := range cards {
+		n += c.Total
+	}
+	return n
+}
+
+// UnmarshalJSON decodes a deck, wrapped in a data field or not. Decks listing
+// their sideboard as sideboardTotal of each card are split into main deck and
+// sideboard, and the hero is taken from the cards if it isn't set explicitly.
+func (d *Deck) UnmarshalJSON(data []byte) error {
+	var wrapped struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err == nil && len(wrapped.Data) > 0 && wrapped.Data[0] == '{' {
+		data = wrapped.Data
+	}
+
+	type deck Deck
+	var raw deck
+	_, drift, err := decodeFields("deck", data, &raw)
+	if err != nil {
+		return err
+	}
+	*d = Deck(raw)
+
+	if len(d.Sideboard) == 0 {
+		main := d.Cards[:0]
+		for _, c := range d.Cards {
+			if c.Card.SideboardTotal > 0 {
+				d.Sideboard = append(d.Sideboard, DeckCard{Card: c.Card, Total: c.Card.SideboardTotal})
+			}
+			if c.Total > 0 {
+				main = append(main, c)
+			}
+		}
+		d.Cards = main
+	}
+
+	if d.Hero.Identifier == "" {
+		cards := d.Cards[:0]
+		for _, c := range d.Cards {
+			if c.Card.Type == "hero" && d.Hero.Identifier == "" {
+				d.Hero = c.Card
+				continue
+			}
+			cards = append(cards, c)
+		}
+		d.Cards = cards
+	}
+
+	drift = append(drift, d.Hero.drift...)
+	for _, c := range d.Cards {
+		drift = append(drift, c.Card.drift...)
+	}
+	for _, c := range d.Sideboard {
+		drift = append(drift, c.Card.drift...)
+	}
+	d.drift = drift
+	return nil
+}
+
+// UnmarshalJSON decodes a card of a deck, the number of copies is
+// expected in the total field next to the card's own fields.
+func (c *DeckCard) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	var total FlexInt
+	if raw, ok := fields["total"]; ok {
+		delete(fields, "total")
+		if err := json.Unmarshal(raw, &total); err != nil {
+			return fmt.Errorf("invalid deck card total: %w", err)
+		}
+	}
+
+	cardData, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	var card Card
+	if err := json.Unmarshal(cardData, &card); err != nil {
+		return err
+	}
+
+	*c = DeckCard{Card: card, Total: int(total)}
+	return nil
+}
+
+// MarshalJSON encodes a card of a deck as the card with an additional total field
+func (c DeckCard) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(c.Card)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["total"] = json.RawMessage(fmt.Sprint(c.Total))
+	return json.Marshal(fields)
+}
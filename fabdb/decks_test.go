@@ -0,0 +1,101 @@
+package fabdb
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestClientGetDeck(t *testing.T) {
+	tests := []struct {
+		name      string
+		slug      string
+		body      string
+		hero      string
+		cards     map[string]int
+		sideboard map[string]int
+	}{
+		{
+			name:      "sideboard totals",
+			slug:      "aBcDeFgH",
+			body:      `{"data":{"slug":"aBcDeFgH","hero":{"identifier":"dorinthea"},"cards":[{"identifier":"snatch-red","total":2,"sideboardTotal":1},{"identifier":"sink-below-red","total":0,"sideboardTotal":3},{"identifier":"dawnblade","total":1}]}}`,
+			hero:      "dorinthea",
+			cards:     map[string]int{"snatch-red": 2, "dawnblade": 1},
+			sideboard: map[string]int{"snatch-red": 1, "sink-below-red": 3},
+		},
+		{
+			name:      "explicit sideboard",
+			slug:      "https://fabdb.net/decks/aBcDeFgH/",
+			body:      `{"slug":"aBcDeFgH","hero":{"identifier":"dorinthea"},"cards":[{"identifier":"snatch-red","total":2,"sideboardTotal":1}],"sideboard":[{"identifier":"sink-below-red","total":3}]}`,
+			hero:      "dorinthea",
+			cards:     map[string]int{"snatch-red": 2},
+			sideboard: map[string]int{"sink-below-red": 3},
+		},
+		{
+			name:  "hero in cards",
+			slug:  "aBcDeFgH",
+			body:  `{"data":{"slug":"aBcDeFgH","cards":[{"identifier":"dorinthea","type":"hero","total":1},{"identifier":"snatch-red","total":"3"}]}}`,
+			hero:  "dorinthea",
+			cards: map[string]int{"snatch-red": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/decks/aBcDeFgH" {
+					writeJSON(w, http.StatusNotFound, `{}`)
+					return
+				}
+				writeJSON(w, http.StatusOK, tt.body)
+			})
+
+			deck, err := client.GetDeck(context.Background(), tt.slug)
+			if err != nil {
+				t.Fatalf("GetDeck() error = %v", err)
+			}
+			if deck.Hero.Identifier != tt.hero {
+				t.Errorf("GetDeck() hero = %q, want %q", deck.Hero.Identifier, tt.hero)
+			}
+			if got := deckTotals(deck.Cards); !reflect.DeepEqual(got, tt.cards) {
+				t.Errorf("GetDeck() cards = %v, want %v", got, tt.cards)
+			}
+			if got := deckTotals(deck.Sideboard); !reflect.DeepEqual(got, tt.sideboard) {
+				t.Errorf("GetDeck() sideboard = %v, want %v", got, tt.sideboard)
+			}
+		})
+	}
+}
+
+func TestDeckSlug(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "aBcDeFgH", want: "aBcDeFgH"},
+		{in: " aBcDeFgH\n", want: "aBcDeFgH"},
+		{in: "https://fabdb.net/decks/aBcDeFgH", want: "aBcDeFgH"},
+		{in: "https://fabdb.net/decks/aBcDeFgH/?tab=cards", want: "aBcDeFgH"},
+		{in: "fabdb.net/decks/aBcDeFgH", want: "aBcDeFgH"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := DeckSlug(tt.in); got != tt.want {
+				t.Errorf("DeckSlug() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func deckTotals(cards []DeckCard) map[string]int {
+	if len(cards) == 0 {
+		return nil
+	}
+	m := make(map[string]int)
+	for _, c := range cards {
+		m[c.Card.Identifier] = c.Total
+	}
+	return m
+}
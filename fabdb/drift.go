@@ -0,0 +1,157 @@
+package fabdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Kinds of schema drift
+const (
+	// DriftUnknownField is reported for fields unknown to this client
+	DriftUnknownField = "unknown_field"
+	// DriftTypeMismatch is reported for fields that could not be decoded into the expected type
+	DriftTypeMismatch = "type_mismatch"
+	// DriftInvalidItem is reported for list items that could not be decoded at all
+	DriftInvalidItem = "invalid_item"
+)
+
+// SchemaDrift describes a part of a fabdb.net response that does not match
+// the schema expected by this client. Responses are decoded on a best effort
+// basis, SchemaDrift is reported to notice upstream changes early.
+type SchemaDrift struct {
+	// Resource is the decoded resource, e.g. "card"
+	Resource string
+	// Field is the JSON field name
+	Field string
+	// Kind is one of DriftUnknownField, DriftTypeMismatch or DriftInvalidItem
+	Kind string
+	// Err is the decoding error, if any
+	Err error
+}
+
+func (d SchemaDrift) String() string {
+	if d.Err != nil {
+		return fmt.Sprintf("%s.%s: %s: %v", d.Resource, d.Field, d.Kind, d.Err)
+	}
+	return fmt.Sprintf("%s.%s: %s", d.Resource, d.Field, d.Kind)
+}
+
+// DriftHandler is called for every schema drift detected in a response
+type DriftHandler func(d SchemaDrift)
+
+// WithSchemaDriftHandler sets the function notified about schema drift
+func WithSchemaDriftHandler(fn DriftHandler) ClientOptions {
+	return func(c *Client) {
+		c.driftHandler = fn
+	}
+}
+
+func (c *Client) reportDrift(drift []SchemaDrift) {
+	if c.driftHandler == nil {
+		return
+	}
+	for _, d := range drift {
+		c.driftHandler(d)
+	}
+}
+
+// decodeFields decodes the JSON object data into the struct pointed to by v
+// one field at a time. Fields that fail to decode are left empty and
+// reported as drift instead of failing the whole object. Fields unknown to
+// the struct are returned.
+func decodeFields(resource string, data []byte, v interface{}) (map[string]json.RawMessage, []SchemaDrift, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, nil, err
+	}
+	extra, drift := decodeFieldMap(resource, fields, v)
+	return extra, drift, nil
+}
+
+// decodeFieldMap is like decodeFields for an object that has been split into its fields already
+func decodeFieldMap(resource string, fields map[string]json.RawMessage, v interface{}) (map[string]json.RawMessage, []SchemaDrift) {
+	var drift []SchemaDrift
+	rv := reflect.ValueOf(v).Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if f.PkgPath != "" || name == "" || name == "-" {
+			continue
+		}
+
+		raw, ok := fields[name]
+		if !ok {
+			continue
+		}
+		delete(fields, name)
+
+		field := rv.Field(i).Addr().Interface()
+		if err := json.Unmarshal(raw, field); err != nil {
+			rv.Field(i).Set(reflect.Zero(f.Type))
+
+			// decode nested objects field by field as well, to keep as much of them as possible
+			var nested map[string]json.RawMessage
+			if _, ok := field.(json.Unmarshaler); !ok && f.Type.Kind() == reflect.Struct && json.Unmarshal(raw, &nested) == nil {
+				_, nestedDrift := decodeFieldMap(resource+"."+name, nested, field)
+				drift = append(drift, nestedDrift...)
+				continue
+			}
+
+			drift = append(drift, SchemaDrift{Resource: resource, Field: name, Kind: DriftTypeMismatch, Err: err})
+		}
+	}
+
+	for name := range fields {
+		drift = append(drift, SchemaDrift{Resource: resource, Field: name, Kind: DriftUnknownField})
+	}
+
+	if len(fields) == 0 {
+		fields = nil
+	}
+	return fields, drift
+}
+
+// FlexInt is an integer that is decoded from JSON numbers as well as numeric strings
+type FlexInt int
+
+// UnmarshalJSON accepts numbers, numeric strings and null
+func (i *FlexInt) UnmarshalJSON(data []byte) error {
+	s := Stat{}
+	if err := s.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	if !s.Valid && string(data) != "null" && string(data) != `""` {
+		return fmt.Errorf("invalid integer %s", data)
+	}
+	*i = FlexInt(s.Value)
+	return nil
+}
+
+// RawStats are the stats of a card as provided by fabdb.net, keyed by stat
+// name. Values are decoded from strings as well as numbers.
+type RawStats map[string]string
+
+// UnmarshalJSON accepts string, number and null values
+func (s *RawStats) UnmarshalJSON(data []byte) error {
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	*s = make(RawStats, len(values))
+	for k, v := range values {
+		switch v := v.(type) {
+		case string:
+			(*s)[k] = v
+		case float64:
+			(*s)[k] = fmt.Sprintf("%g", v)
+		case nil:
+		default:
+			return fmt.Errorf("invalid value for stat %q: %v", k, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package fabdb
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestClientSchemaDrift(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		cards []string
+		drift []string
+	}{
+		{
+			name:  "matching schema",
+			body:  `{"data":[{"identifier":"snatch-red","name":"Snatch"}]}`,
+			cards: []string{"snatch-red"},
+		},
+		{
+			name:  "unknown fields",
+			body:  `{"data":[{"identifier":"snatch-red","flavour":"Mine!"}],"version":2}`,
+			cards: []string{"snatch-red"},
+			drift: []string{"card.flavour: unknown_field", "search.version: unknown_field"},
+		},
+		{
+			name:  "type mismatch",
+			body:  `{"data":[{"identifier":"snatch-red","name":42}]}`,
+			cards: []string{"snatch-red"},
+			drift: []string{"card.name: type_mismatch"},
+		},
+		{
+			name:  "invalid and null items",
+			body:  `{"data":[{"identifier":"snatch-red"},null,"snatch-blue",{"identifier":"snatch-yellow"}]}`,
+			cards: []string{"snatch-red", "snatch-yellow"},
+			drift: []string{"search.data: invalid_item", "search.data: invalid_item"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var drift []string
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusOK, tt.body)
+			}, WithSchemaDriftHandler(func(d SchemaDrift) {
+				mu.Lock()
+				defer mu.Unlock()
+				drift = append(drift, d.Resource+"."+d.Field+": "+d.Kind)
+			}))
+
+			cards, err := client.SearchCards(context.Background(), SearchOptions{})
+			if err != nil {
+				t.Fatalf("SearchCards() error = %v", err)
+			}
+
+			var identifiers []string
+			for _, c := range cards {
+				identifiers = append(identifiers, c.Identifier)
+			}
+			if !reflect.DeepEqual(identifiers, tt.cards) {
+				t.Errorf("SearchCards() = %v, want %v", identifiers, tt.cards)
+			}
+
+			sort.Strings(drift)
+			if !reflect.DeepEqual(drift, tt.drift) {
+				t.Errorf("SearchCards() drift = %v, want %v", drift, tt.drift)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package fabdb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// maxErrorBodySize limits how much of an error response is decoded
+const maxErrorBodySize = 64 << 10
+
+// ErrResponseTooLarge is returned if a response body exceeds the maximum response size of the client
+var ErrResponseTooLarge = errors.New("response body exceeds maximum size")
+
+// APIError is returned for unsuccessful responses of the fabdb.net API.
+// Depending on the status code it is wrapped by NotFoundError,
+// RateLimitedError or ServerError.
+type APIError struct {
+	// StatusCode is the HTTP response status code
+	StatusCode int `json:"-"`
+	// Message is the error message contained in the response body, if any
+	Message string `json:"message"`
+	// Errors contains validation errors per request parameter, if any
+	Errors map[string][]string `json:"errors"`
+
+	message string
+}
+
+func (a APIError) Error() string {
+	msg := a.message
+	if len(msg) == 0 {
+		msg = fmt.Sprintf("HTTP Requests failed with statuscode %d", a.StatusCode)
+	}
+	if len(a.Message) > 0 {
+		msg = fmt.Sprintf("%s: %s", msg, a.Message)
+	}
+	return msg
+}
+
+// NotFoundError is returned if the requested resource does not exist
+type NotFoundError struct {
+	APIError
+}
+
+func (e NotFoundError) Unwrap() error {
+	return e.APIError
+}
+
+// RateLimitedError is returned if fabdb.net rejected a request because too
+// many requests have been sent.
+type RateLimitedError struct {
+	APIError
+	// RetryAfter is the time to wait before sending another request, if known
+	RetryAfter time.Duration
+}
+
+func (e RateLimitedError) Unwrap() error {
+	return e.APIError
+}
+
+// ServerError is returned if fabdb.net failed to handle a request
+type ServerError struct {
+	APIError
+}
+
+func (e ServerError) Unwrap() error {
+	return e.APIError
+}
+
+// DecodeError is returned if a response can't be decoded
+type DecodeError struct {
+	Err error
+}
+
+func (e DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode response: %v", e.Err)
+}
+
+func (e DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// TransportError is returned if a request could not be sent or the response
+// could not be read, e.g. because of network errors or timeouts.
+type TransportError struct {
+	Err error
+}
+
+func (e TransportError) Error() string {
+	return fmt.Sprintf("Error calling the API endpoint: %v", e.Err)
+}
+
+func (e TransportError) Unwrap() error {
+	return e.Err
+}
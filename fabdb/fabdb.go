@@ -2,40 +2,84 @@ package fabdb
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"net/http"
+	"net/url"
 	"strings"
 )
 
+// ErrNoCards is returned if a search does not match any cards
+var ErrNoCards = errors.New("no cards found")
+
+// defaultPageSize is the number of cards requested per page
+const defaultPageSize = 30
+
 type FabDBClient struct {
-	client *Client
+	client   *Client
+	pageSize int
 }
 
-func NewFabDBClient() *FabDBClient {
-	return &FabDBClient{
-		client: NewClient(),
+// FabDBClientOption allows for options to be passed into the FabDBClient for customization
+type FabDBClientOption func(*FabDBClient)
+
+func NewFabDBClient(options ...FabDBClientOption) *FabDBClient {
+	c := &FabDBClient{
+		client:   NewClient(),
+		pageSize: defaultPageSize,
 	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+
+	return c
 }
 
-func (c *FabDBClient) ListCards(ctx context.Context, query string) ([]Card, error) {
-	resp, err := c.client.get(ctx, fmt.Sprintf("/cards?per_page=30&keywords=%s&page=1&use-case=browse", query))
-	if err != nil {
-		return nil, err
+// WithClient sets the underlying API client used for requests
+func WithClient(client *Client) FabDBClientOption {
+	return func(c *FabDBClient) {
+		c.client = client
 	}
+}
 
-	var result FaBDBSearchResponse
-	if err := c.client.decodeJSON(resp, &result); err != nil {
+// Client returns the underlying API client used for requests
+func (c *FabDBClient) Client() *Client {
+	return c.client
+}
+
+// WithPageSize sets the number of cards requested per page
+func WithPageSize(n int) FabDBClientOption {
+	return func(c *FabDBClient) {
+		if n > 0 {
+			c.pageSize = n
+		}
+	}
+}
+
+func (c *FabDBClient) ListCards(ctx context.Context, query string) ([]Card, error) {
+	return c.SearchCards(ctx, SearchOptions{Keywords: query})
+}
+
+// SearchCards returns a single page of cards matching the search options
+func (c *FabDBClient) SearchCards(ctx context.Context, opts SearchOptions) ([]Card, error) {
+	result, err := c.searchPage(ctx, opts)
+	if err != nil {
 		return []Card{}, err
 	}
 
 	if len(result.Data) <= 0 {
-		return []Card{}, fmt.Errorf("JSON response does not have any card fields")
+		return []Card{}, ErrNoCards
 	}
 
 	return result.Data, nil
 }
 
+func (c *FabDBClient) searchPage(ctx context.Context, opts SearchOptions) (FaBDBSearchResponse, error) {
+	return c.getSearchPage(ctx, c.client.apiEndpoint+"/cards?"+c.withDefaults(opts).Encode())
+}
+
 func (c *FabDBClient) GetCard(ctx context.Context, identifier string) (Card, error) {
-	resp, err := c.client.get(ctx, fmt.Sprintf("/cards/%s", strings.ToLower(identifier)))
+	resp, err := c.client.get(ctx, "/cards/"+url.PathEscape(strings.ToLower(identifier)))
 	if err != nil {
 		return Card{}, err
 	}
@@ -44,6 +88,103 @@ func (c *FabDBClient) GetCard(ctx context.Context, identifier string) (Card, err
 	if err := c.client.decodeJSON(resp, &result); err != nil {
 		return Card{}, err
 	}
+	c.client.reportDrift(result.drift)
+
+	return result, nil
+}
+
+// IterateCards returns a CardIterator paging through all cards matching the
+// search options, starting at opts.Page. Empty options iterate the complete catalogue.
+func (c *FabDBClient) IterateCards(ctx context.Context, opts SearchOptions) *CardIterator {
+	opts = c.withDefaults(opts)
+	return &CardIterator{
+		ctx:    ctx,
+		client: c,
+		next:   c.client.apiEndpoint + "/cards?" + opts.Encode(),
+	}
+}
+
+func (c *FabDBClient) getSearchPage(ctx context.Context, url string) (FaBDBSearchResponse, error) {
+	resp, err := c.client.doWithEndpoint(ctx, "", http.MethodGet, url, nil, nil)
+	if err != nil {
+		return FaBDBSearchResponse{}, err
+	}
 
+	var result FaBDBSearchResponse
+	if err := c.client.decodeJSON(resp, &result); err != nil {
+		return FaBDBSearchResponse{}, err
+	}
+	c.client.reportDrift(result.drift)
 	return result, nil
 }
+
+// CardIterator pages through the results of a card search, following the
+// pagination links of each response until the last page has been reached
+// or the context is cancelled.
+//
+//	it := client.IterateCards(ctx, fabdb.SearchOptions{Class: "ninja"})
+//	for it.Next() {
+//		cards := it.Cards()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type CardIterator struct {
+	ctx    context.Context
+	client *FabDBClient
+	next   string
+	page   FaBDBSearchResponse
+	err    error
+}
+
+// Next fetches the next page of cards. It returns false when all pages have
+// been consumed or an error occurred, which is then returned by Err.
+func (it *CardIterator) Next() bool {
+	if it.err != nil || it.next == "" {
+		return false
+	}
+
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	page, err := it.client.getSearchPage(it.ctx, it.next)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	// the next link decides whether there are more pages, the page numbers
+	// in meta are only informational and may be missing or stale
+	it.page = page
+	it.next = page.Links.Next
+
+	return len(page.Data) > 0
+}
+
+// Cards returns the cards of the current page
+func (it *CardIterator) Cards() []Card {
+	return it.page.Data
+}
+
+// Page returns the number of the current page
+func (it *CardIterator) Page() int {
+	return int(it.page.Meta.CurrentPage)
+}
+
+// LastPage returns the number of the last page of the result set
+func (it *CardIterator) LastPage() int {
+	return int(it.page.Meta.LastPage)
+}
+
+// Total returns the total number of cards in the result set
+func (it *CardIterator) Total() int {
+	return int(it.page.Meta.Total)
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *CardIterator) Err() error {
+	return it.err
+}
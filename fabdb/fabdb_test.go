@@ -0,0 +1,128 @@
+package fabdb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCardIterator(t *testing.T) {
+	tests := []struct {
+		name string
+		// pages are the identifiers of the cards on each page
+		pages [][]string
+		// lastPage is reported in meta, -1 omits the meta
+		lastPage int
+		// links is the number of pages linked as next page
+		links int
+		// failPage responds with a server error for the page
+		failPage int
+		cards    []string
+		err      bool
+	}{
+		{
+			name:     "all pages",
+			pages:    [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+			lastPage: 3,
+			links:    3,
+			cards:    []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:     "no meta",
+			pages:    [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+			lastPage: -1,
+			links:    3,
+			cards:    []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:     "stale meta",
+			pages:    [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+			lastPage: 1,
+			links:    3,
+			cards:    []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:     "no next link",
+			pages:    [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+			lastPage: 3,
+			links:    2,
+			cards:    []string{"a", "b", "c", "d"},
+		},
+		{
+			name:     "failing page",
+			pages:    [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+			lastPage: 3,
+			links:    3,
+			failPage: 2,
+			cards:    []string{"a", "b"},
+			err:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				if page == 0 {
+					page = 1
+				}
+				if page == tt.failPage || page > len(tt.pages) {
+					writeJSON(w, http.StatusInternalServerError, `{}`)
+					return
+				}
+
+				var data []string
+				for _, id := range tt.pages[page-1] {
+					data = append(data, fmt.Sprintf(`{"identifier":%q}`, id))
+				}
+				next := "null"
+				if page < tt.links {
+					next = fmt.Sprintf(`"http://%s/cards?page=%d"`, r.Host, page+1)
+				}
+				meta := ""
+				if tt.lastPage >= 0 {
+					meta = fmt.Sprintf(`,"meta":{"current_page":%d,"last_page":%d}`, page, tt.lastPage)
+				}
+				writeJSON(w, http.StatusOK, fmt.Sprintf(`{"data":[%s],"links":{"next":%s}%s}`, strings.Join(data, ","), next, meta))
+			}, WithRetryPolicy(RetryPolicy{}))
+
+			var cards []string
+			it := client.IterateCards(context.Background(), SearchOptions{})
+			for it.Next() {
+				for _, c := range it.Cards() {
+					cards = append(cards, c.Identifier)
+				}
+			}
+
+			if !reflect.DeepEqual(cards, tt.cards) {
+				t.Errorf("IterateCards() = %v, want %v", cards, tt.cards)
+			}
+			if (it.Err() != nil) != tt.err {
+				t.Errorf("IterateCards() error = %v, want error %t", it.Err(), tt.err)
+			}
+		})
+	}
+}
+
+func TestCardIteratorCanceled(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, fmt.Sprintf(`{"data":[{"identifier":"a"}],"links":{"next":"http://%s/cards?page=2"}}`, r.Host))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := client.IterateCards(ctx, SearchOptions{})
+	if !it.Next() {
+		t.Fatalf("Next() = false, error = %v", it.Err())
+	}
+	cancel()
+	if it.Next() {
+		t.Error("Next() = true after cancel, want false")
+	}
+	if it.Err() != context.Canceled {
+		t.Errorf("Err() = %v, want %v", it.Err(), context.Canceled)
+	}
+}
@@ -0,0 +1,104 @@
+package fabdb
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPMetrics records metrics of requests sent to the fabdb.net API, labeled
+// by endpoint, e.g. "/cards" or "/cards/{id}".
+type HTTPMetrics interface {
+	ObserveFabDBRequest(endpoint, code string, duration time.Duration)
+	IncFabDBRequestsInFlight(endpoint string)
+	DecFabDBRequestsInFlight(endpoint string)
+	AddFabDBResponseBytes(endpoint string, n int)
+}
+
+// InstrumentedHTTPClient wraps a HTTPClient and records request durations,
+// status codes, requests in flight and bytes received.
+type InstrumentedHTTPClient struct {
+	next    HTTPClient
+	metrics HTTPMetrics
+}
+
+// NewInstrumentedHTTPClient returns a HTTPClient recording metrics of all requests sent by next
+func NewInstrumentedHTTPClient(next HTTPClient, m HTTPMetrics) *InstrumentedHTTPClient {
+	return &InstrumentedHTTPClient{
+		next:    next,
+		metrics: m,
+	}
+}
+
+// WithHTTPMetrics instruments the HTTP client of the Client
+func WithHTTPMetrics(m HTTPMetrics) ClientOptions {
+	return func(c *Client) {
+		c.HTTPClient = NewInstrumentedHTTPClient(c.HTTPClient, m)
+	}
+}
+
+// Do sends the request using the wrapped client and records its metrics. A
+// request is in flight until its response body has been closed.
+func (c *InstrumentedHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	endpoint := endpointLabel(req.URL.Path)
+
+	c.metrics.IncFabDBRequestsInFlight(endpoint)
+
+	start := time.Now()
+	resp, err := c.next.Do(req)
+	if err != nil {
+		c.metrics.DecFabDBRequestsInFlight(endpoint)
+		c.metrics.ObserveFabDBRequest(endpoint, "error", time.Since(start))
+		return resp, err
+	}
+	c.metrics.ObserveFabDBRequest(endpoint, strconv.Itoa(resp.StatusCode), time.Since(start))
+
+	if resp.Body == nil {
+		c.metrics.DecFabDBRequestsInFlight(endpoint)
+		return resp, nil
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, endpoint: endpoint, metrics: c.metrics}
+	return resp, nil
+}
+
+// countingBody records the number of bytes read from a response body and
+// ends the request in flight once the body is closed
+type countingBody struct {
+	io.ReadCloser
+	endpoint string
+	metrics  HTTPMetrics
+	once     sync.Once
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.metrics.AddFabDBResponseBytes(b.endpoint, n)
+	}
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	b.once.Do(func() {
+		b.metrics.DecFabDBRequestsInFlight(b.endpoint)
+	})
+	return b.ReadCloser.Close()
+}
+
+// endpointLabel returns a low cardinality label for a request path, replacing
+// resource identifiers with a placeholder, e.g. "/cards/{id}" for "/cards/snatch-red".
+func endpointLabel(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) == 0 || segments[0] == "" {
+		return "/"
+	}
+
+	label := "/" + segments[0]
+	if len(segments) > 1 {
+		label += "/{id}"
+	}
+	return label
+}
@@ -1,7 +1,13 @@
 package fabdb
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
 type FaBDBSearchResponse struct {
-	Data  []Card `json:"data,omitempty"`
+	Data  []Card `json:"data"`
 	Links struct {
 		First string `json:"first"`
 		Last  string `json:"last"`
@@ -9,30 +15,135 @@ type FaBDBSearchResponse struct {
 		Next  string `json:"next"`
 	} `json:"links"`
 	Meta struct {
-		CurrentPage int `json:"current_page"`
-		From        int `json:"from"`
-		LastPage    int `json:"last_page"`
+		CurrentPage FlexInt `json:"current_page"`
+		From        FlexInt `json:"from"`
+		LastPage    FlexInt `json:"last_page"`
 		Links       []struct {
 			URL    string `json:"url"`
 			Label  string `json:"label"`
 			Active bool   `json:"active"`
 		} `json:"links"`
-		Path    string `json:"path"`
-		PerPage string `json:"per_page"`
-		To      int    `json:"to"`
-		Total   int    `json:"total"`
+		Path    string  `json:"path"`
+		PerPage FlexInt `json:"per_page"`
+		To      FlexInt `json:"to"`
+		Total   FlexInt `json:"total"`
 	} `json:"meta"`
+
+	drift []SchemaDrift
+}
+
+// UnmarshalJSON decodes a search response. Cards that can't be decoded and
+// null items are skipped and reported as schema drift instead of failing the
+// whole response.
+func (r *FaBDBSearchResponse) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	var items []json.RawMessage
+	if rawData, ok := fields["data"]; ok {
+		delete(fields, "data")
+		if err := json.Unmarshal(rawData, &items); err != nil {
+			return fmt.Errorf("invalid search response data: %w", err)
+		}
+	}
+
+	type response FaBDBSearchResponse
+	var raw response
+	_, drift := decodeFieldMap("search", fields, &raw)
+
+	*r = FaBDBSearchResponse(raw)
+	r.Data = make([]Card, 0, len(items))
+	for i, item := range items {
+		if string(bytes.TrimSpace(item)) == "null" {
+			drift = append(drift, SchemaDrift{Resource: "search", Field: "data", Kind: DriftInvalidItem, Err: fmt.Errorf("item %d is null", i)})
+			continue
+		}
+
+		var card Card
+		if err := json.Unmarshal(item, &card); err != nil {
+			drift = append(drift, SchemaDrift{Resource: "search", Field: "data", Kind: DriftInvalidItem, Err: fmt.Errorf("item %d: %w", i, err)})
+			continue
+		}
+		drift = append(drift, card.drift...)
+		r.Data = append(r.Data, card)
+	}
+	r.drift = drift
+	return nil
 }
 
 type Card struct {
 	Identifier     string      `json:"identifier"`
 	Name           string      `json:"name"`
 	Keywords       []string    `json:"keywords"`
+	Stats          RawStats    `json:"stats"`
 	Text           string      `json:"text"`
 	Rarity         string      `json:"rarity"`
 	Image          string      `json:"image"`
 	SideboardTotal int         `json:"sideboardTotal"`
 	Printings      []Printings `json:"printings"`
+
+	// Structured stats, derived from Stats and Keywords if missing in the payload
+	Cost      Stat     `json:"cost"`
+	Pitch     Stat     `json:"pitch"`
+	Power     Stat     `json:"power"`
+	Defense   Stat     `json:"defense"`
+	Life      Stat     `json:"life"`
+	Intellect Stat     `json:"intellect"`
+	Class     string   `json:"class,omitempty"`
+	Talent    string   `json:"talent,omitempty"`
+	Type      string   `json:"type,omitempty"`
+	Subtypes  []string `json:"subtypes,omitempty"`
+
+	// Extra contains fields of the payload unknown to this client
+	Extra map[string]json.RawMessage `json:"-"`
+
+	drift []SchemaDrift
+}
+
+// UnmarshalJSON decodes a card and derives its structured stats. Fields that
+// can't be decoded are left empty and reported as schema drift, unknown
+// fields are kept in Extra.
+func (c *Card) UnmarshalJSON(data []byte) error {
+	type card Card
+	var raw card
+	extra, drift, err := decodeFields("card", data, &raw)
+	if err != nil {
+		return err
+	}
+
+	*c = Card(raw)
+	c.Extra = extra
+	c.drift = drift
+	if c.Keywords == nil {
+		c.Keywords = []string{}
+	}
+	if c.Printings == nil {
+		c.Printings = []Printings{}
+	}
+	c.deriveStats()
+	return nil
+}
+
+// MarshalJSON encodes a card including the unknown fields kept in Extra
+func (c Card) MarshalJSON() ([]byte, error) {
+	type card Card
+	data, err := json.Marshal(card(c))
+	if err != nil || len(c.Extra) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range c.Extra {
+		if _, ok := fields[k]; !ok {
+			fields[k] = v
+		}
+	}
+	return json.Marshal(fields)
 }
 
 type Printings struct {
@@ -44,13 +155,7 @@ type Printings struct {
 	Sku      struct {
 		Sku    string `json:"sku"`
 		Finish string `json:"finish"`
-		Set    struct {
-			ID         string `json:"id"`
-			Name       string `json:"name"`
-			Released   string `json:"released"`
-			Browseable bool   `json:"browseable"`
-			Draftable  bool   `json:"draftable"`
-		} `json:"set"`
+		Set    Set    `json:"set"`
 		Number string `json:"number"`
 	} `json:"sku"`
 	Set     string `json:"set"`
@@ -59,16 +164,11 @@ type Printings struct {
 	}
 }
 
-// UniqueSetsFromPrintings is unused for now
+// UniqueSetsFromPrintings returns the names of all sets a card has been printed in
 func UniqueSetsFromPrintings(printings []Printings) []string {
-	sets := make(map[string]interface{})
-	for _, p := range printings {
-		sets[p.Sku.Set.Name] = ""
-	}
-
 	res := []string{}
-	for k, _ := range sets {
-		res = append(res, k)
+	for _, set := range SetsFromPrintings(printings) {
+		res = append(res, set.Name)
 	}
 	return res
 }
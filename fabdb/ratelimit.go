@@ -0,0 +1,126 @@
+package fabdb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimit configures the client side token bucket limiting requests
+// towards the API.
+type RateLimit struct {
+	// PerSecond is the number of requests allowed per second on average
+	PerSecond float64
+	// Burst is the number of requests that may be sent at once
+	Burst int
+	// Reject makes requests fail with a ThrottledError if the limit is
+	// exceeded. By default they are queued until a token is available or
+	// their context is done.
+	Reject bool
+}
+
+// WithRateLimit limits the requests sent by the client
+func WithRateLimit(l RateLimit) ClientOptions {
+	return func(c *Client) {
+		c.limiter = newRateLimiter(l)
+	}
+}
+
+// ThrottledError is returned if the client side rate limit does not allow
+// a request to be sent.
+type ThrottledError struct {
+	// Wait is the time until the next request would be allowed
+	Wait time.Duration
+}
+
+func (e ThrottledError) Error() string {
+	return fmt.Sprintf("client rate limit exceeded, next request allowed in %s", e.Wait)
+}
+
+type rateLimiter struct {
+	limit RateLimit
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(l RateLimit) *rateLimiter {
+	if l.PerSecond <= 0 {
+		return nil
+	}
+	if l.Burst < 1 {
+		l.Burst = 1
+	}
+	return &rateLimiter{
+		limit:  l,
+		now:    time.Now,
+		tokens: float64(l.Burst),
+	}
+}
+
+// reserve takes a token from the bucket and returns how long the caller has
+// to wait until the token becomes valid. The balance may become negative,
+// which queues subsequent callers behind it.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.limit.PerSecond
+		if burst := float64(l.limit.Burst); l.tokens > burst {
+			l.tokens = burst
+		}
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.limit.PerSecond * float64(time.Second))
+}
+
+// cancel returns a reserved token to the bucket
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+}
+
+// wait blocks until a request may be sent. It fails with a ThrottledError if
+// the limiter rejects excess requests or the context deadline expires first.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	d := l.reserve()
+	if d == 0 {
+		return nil
+	}
+
+	if l.limit.Reject {
+		l.cancel()
+		return ThrottledError{Wait: d}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(d).After(deadline) {
+		l.cancel()
+		return ThrottledError{Wait: d}
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
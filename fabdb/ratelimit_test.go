@@ -0,0 +1,134 @@
+package fabdb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   RateLimit
+		elapsed []time.Duration
+		waits   []time.Duration
+	}{
+		{
+			name:    "burst",
+			limit:   RateLimit{PerSecond: 1, Burst: 3},
+			elapsed: []time.Duration{0, 0, 0, 0},
+			waits:   []time.Duration{0, 0, 0, time.Second},
+		},
+		{
+			name:    "queued behind each other",
+			limit:   RateLimit{PerSecond: 2, Burst: 1},
+			elapsed: []time.Duration{0, 0, 0},
+			waits:   []time.Duration{0, 500 * time.Millisecond, time.Second},
+		},
+		{
+			name:    "refilled over time",
+			limit:   RateLimit{PerSecond: 2, Burst: 1},
+			elapsed: []time.Duration{0, 500 * time.Millisecond, 250 * time.Millisecond},
+			waits:   []time.Duration{0, 0, 250 * time.Millisecond},
+		},
+		{
+			name:    "refill capped at burst",
+			limit:   RateLimit{PerSecond: 10, Burst: 2},
+			elapsed: []time.Duration{0, time.Minute, 0, 0},
+			waits:   []time.Duration{0, 0, 0, 100 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+			l := newRateLimiter(tt.limit)
+			l.now = func() time.Time { return now }
+
+			for i, elapsed := range tt.elapsed {
+				now = now.Add(elapsed)
+				if got := l.reserve(); got != tt.waits[i] {
+					t.Errorf("reserve() #%d = %s, want %s", i+1, got, tt.waits[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		limit    RateLimit
+		timeout  time.Duration
+		requests int
+		sent     int32
+		minTime  time.Duration
+		err      bool
+	}{
+		{
+			name:     "within burst",
+			limit:    RateLimit{PerSecond: 1, Burst: 3},
+			requests: 3,
+			sent:     3,
+		},
+		{
+			name:     "rejected",
+			limit:    RateLimit{PerSecond: 1, Burst: 2, Reject: true},
+			requests: 3,
+			sent:     2,
+			err:      true,
+		},
+		{
+			name:     "queued",
+			limit:    RateLimit{PerSecond: 20, Burst: 1},
+			requests: 3,
+			sent:     3,
+			minTime:  100 * time.Millisecond,
+		},
+		{
+			name:     "queued beyond deadline",
+			limit:    RateLimit{PerSecond: 1, Burst: 1},
+			timeout:  100 * time.Millisecond,
+			requests: 2,
+			sent:     1,
+			err:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent int32
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&sent, 1)
+				writeJSON(w, http.StatusOK, `{"identifier":"snatch-red"}`)
+			}, WithRateLimit(tt.limit))
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			var err error
+			for i := 0; i < tt.requests && err == nil; i++ {
+				_, err = client.GetCard(ctx, "snatch-red")
+			}
+
+			if got := atomic.LoadInt32(&sent); got != tt.sent {
+				t.Errorf("GetCard() sent %d requests, want %d", got, tt.sent)
+			}
+			var throttled ThrottledError
+			if tt.err != errors.As(err, &throttled) {
+				t.Errorf("GetCard() error = %v, want throttled %t", err, tt.err)
+			}
+			if d := time.Since(start); d < tt.minTime {
+				t.Errorf("GetCard() took %s, want at least %s", d, tt.minTime)
+			}
+		})
+	}
+}
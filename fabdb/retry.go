@@ -0,0 +1,146 @@
+package fabdb
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy configures how failed requests are retried. Only idempotent
+// requests are retried, on network errors, 429 and 5xx responses.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry, doubled on every attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between two attempts. Requests are not retried
+	// if the server asks to wait longer than MaxDelay using Retry-After. Zero
+	// means no cap.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used by NewClient
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+	}
+}
+
+// NoRetryPolicy returns a retry policy that never retries
+func NoRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// WithRetryPolicy sets the retry policy of the client
+func WithRetryPolicy(p RetryPolicy) ClientOptions {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+var (
+	jitterMu  sync.Mutex
+	jitterRnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// backoff returns the jittered delay before the given retry, starting at 1
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < retry && (p.MaxDelay <= 0 || d < p.MaxDelay) && d <= math.MaxInt64/2; i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	// full jitter, see https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+	jitterMu.Lock()
+	defer jitterMu.Unlock()
+	return time.Duration(jitterRnd.Int63n(int64(d) + 1))
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// shouldRetry reports whether a failed attempt may succeed when retried
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if resp == nil {
+		return err != nil
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryAfter parses the Retry-After header of a response, given either as
+// seconds or as HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// wait blocks for the given duration or until the context is done. It
+// returns false immediately if the context deadline expires before.
+func wait(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(d).After(deadline) {
+		return false
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// discard drains and closes the body of a response that is not used anymore,
+// so the underlying connection can be reused.
+func discard(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+	_ = resp.Body.Close()
+}
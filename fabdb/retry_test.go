@@ -0,0 +1,140 @@
+package fabdb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testResponse struct {
+	code       int
+	retryAfter string
+}
+
+func TestClientRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []testResponse
+		attempts  int32
+		err       interface{}
+	}{
+		{
+			name:      "server error is retried",
+			responses: []testResponse{{code: http.StatusServiceUnavailable}, {code: http.StatusOK}},
+			attempts:  2,
+		},
+		{
+			name:      "rate limited with retry after",
+			responses: []testResponse{{code: http.StatusTooManyRequests, retryAfter: "0"}, {code: http.StatusOK}},
+			attempts:  2,
+		},
+		{
+			name:      "retry after beyond max delay",
+			responses: []testResponse{{code: http.StatusTooManyRequests, retryAfter: "60"}, {code: http.StatusOK}},
+			attempts:  1,
+			err:       &RateLimitedError{},
+		},
+		{
+			name:      "attempts exhausted",
+			responses: []testResponse{{code: http.StatusBadGateway}, {code: http.StatusBadGateway}, {code: http.StatusBadGateway}, {code: http.StatusOK}},
+			attempts:  3,
+			err:       &ServerError{},
+		},
+		{
+			name:      "not found is not retried",
+			responses: []testResponse{{code: http.StatusNotFound}, {code: http.StatusOK}},
+			attempts:  1,
+			err:       &NotFoundError{},
+		},
+		{
+			name:      "client error is not retried",
+			responses: []testResponse{{code: http.StatusForbidden}, {code: http.StatusOK}},
+			attempts:  1,
+			err:       &APIError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				resp := tt.responses[atomic.AddInt32(&attempts, 1)-1]
+				if resp.retryAfter != "" {
+					w.Header().Set("Retry-After", resp.retryAfter)
+				}
+				writeJSON(w, resp.code, `{"identifier":"snatch-red","name":"Snatch"}`)
+			})
+
+			card, err := client.GetCard(context.Background(), "snatch-red")
+			if got := atomic.LoadInt32(&attempts); got != tt.attempts {
+				t.Errorf("GetCard() sent %d requests, want %d", got, tt.attempts)
+			}
+			if tt.err == nil {
+				if err != nil || card.Identifier != "snatch-red" {
+					t.Errorf("GetCard() = %q, %v, want snatch-red", card.Identifier, err)
+				}
+				return
+			}
+			if !errors.As(err, tt.err) {
+				t.Errorf("GetCard() error = %v (%T), want %T", err, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	var attempts int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			writeJSON(w, http.StatusTooManyRequests, `{}`)
+			return
+		}
+		writeJSON(w, http.StatusOK, `{"identifier":"snatch-red"}`)
+	}, WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second}))
+
+	start := time.Now()
+	if _, err := client.GetCard(context.Background(), "snatch-red"); err != nil {
+		t.Fatalf("GetCard() error = %v", err)
+	}
+	if d := time.Since(start); d < time.Second {
+		t.Errorf("GetCard() retried after %s, want Retry-After of 1s", d)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		retry  int
+		max    time.Duration
+	}{
+		{name: "first retry", policy: RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: time.Second}, retry: 1, max: 10 * time.Millisecond},
+		{name: "doubled", policy: RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: time.Second}, retry: 3, max: 40 * time.Millisecond},
+		{name: "capped", policy: RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 15 * time.Millisecond}, retry: 5, max: 15 * time.Millisecond},
+		{name: "no cap", policy: RetryPolicy{BaseDelay: 10 * time.Millisecond}, retry: 5, max: 160 * time.Millisecond},
+		{name: "no delay", policy: RetryPolicy{}, retry: 3, max: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var longest time.Duration
+			for i := 0; i < 500; i++ {
+				d := tt.policy.backoff(tt.retry)
+				if d < 0 || d > tt.max {
+					t.Fatalf("backoff(%d) = %s, want between 0 and %s", tt.retry, d, tt.max)
+				}
+				if d > longest {
+					longest = d
+				}
+			}
+			// full jitter spreads the delays over the whole range
+			if longest < tt.max/2 {
+				t.Errorf("backoff(%d) at most %s, want up to %s", tt.retry, longest, tt.max)
+			}
+		})
+	}
+}
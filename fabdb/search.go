@@ -0,0 +1,126 @@
+package fabdb
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const defaultUseCase = "browse"
+
+// SearchOptions describes a card search against the fabdb.net API.
+// Empty fields are omitted from the request.
+type SearchOptions struct {
+	// Keywords is a free text search over card names and keywords
+	Keywords string
+	// Class restricts results to a hero class, e.g. "ninja"
+	Class string
+	// Talent restricts results to a talent, e.g. "light"
+	Talent string
+	// Type restricts results to a card type, e.g. "action"
+	Type string
+	// Set restricts results to a set code, e.g. "WTR"
+	Set string
+	// Rarity restricts results to a rarity code, e.g. "C", "R" or "M"
+	Rarity string
+	// Pitch restricts results to a pitch value, e.g. "1" for red cards
+	Pitch string
+	// Cost restricts results to a resource cost
+	Cost string
+
+	// Page is the page of the result set to fetch, starting at 1
+	Page int
+	// PerPage is the number of cards per page
+	PerPage int
+	// UseCase is passed on to fabdb, defaults to "browse"
+	UseCase string
+}
+
+// Values returns the search options as URL query parameters
+func (o SearchOptions) Values() url.Values {
+	v := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+
+	set("keywords", o.Keywords)
+	set("class", o.Class)
+	set("talent", o.Talent)
+	set("type", o.Type)
+	set("set", o.Set)
+	set("rarity", o.Rarity)
+	set("pitch", o.Pitch)
+	set("cost", o.Cost)
+	set("use-case", o.UseCase)
+	if o.Page > 0 {
+		v.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.PerPage > 0 {
+		v.Set("per_page", strconv.Itoa(o.PerPage))
+	}
+	return v
+}
+
+// Encode returns the search options as URL encoded query string
+func (o SearchOptions) Encode() string {
+	return o.Values().Encode()
+}
+
+// Match reports whether the card satisfies the filters of the search options.
+// It is used to evaluate searches against locally stored cards, paging
+// options are ignored.
+func (o SearchOptions) Match(card Card) bool {
+	if terms := strings.Fields(strings.ToLower(o.Keywords)); len(terms) > 0 {
+		haystack := strings.ToLower(card.Name + " " + card.Identifier + " " + strings.Join(card.Keywords, " "))
+		for _, t := range terms {
+			if !strings.Contains(haystack, t) {
+				return false
+			}
+		}
+	}
+
+	for _, keyword := range []string{o.Class, o.Talent, o.Type} {
+		if keyword != "" && !card.HasKeyword(keyword) {
+			return false
+		}
+	}
+
+	if o.Set != "" && !inSet(card, o.Set) {
+		return false
+	}
+	if o.Rarity != "" && !strings.EqualFold(card.Rarity, o.Rarity) {
+		return false
+	}
+	if o.Pitch != "" && card.Pitch.String() != o.Pitch {
+		return false
+	}
+	if o.Cost != "" && card.Cost.String() != o.Cost {
+		return false
+	}
+	return true
+}
+
+func inSet(card Card, set string) bool {
+	for _, p := range card.Printings {
+		if strings.EqualFold(p.Set, set) || strings.EqualFold(p.Sku.Set.ID, set) {
+			return true
+		}
+	}
+	return false
+}
+
+// withDefaults fills unset paging options with the client defaults
+func (c *FabDBClient) withDefaults(o SearchOptions) SearchOptions {
+	if o.Page <= 0 {
+		o.Page = 1
+	}
+	if o.PerPage <= 0 {
+		o.PerPage = c.pageSize
+	}
+	if o.UseCase == "" {
+		o.UseCase = defaultUseCase
+	}
+	return o
+}
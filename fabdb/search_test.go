@@ -0,0 +1,117 @@
+package fabdb
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestSearchOptionsEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		opts SearchOptions
+		want string
+	}{
+		{name: "empty", opts: SearchOptions{}, want: ""},
+		{name: "keywords", opts: SearchOptions{Keywords: "snatch red"}, want: "keywords=snatch+red"},
+		{
+			name: "filters",
+			opts: SearchOptions{Class: "ninja", Talent: "light", Type: "action", Set: "WTR", Rarity: "M", Pitch: "1", Cost: "0"},
+			want: "class=ninja&cost=0&pitch=1&rarity=M&set=WTR&talent=light&type=action",
+		},
+		{
+			name: "paging",
+			opts: SearchOptions{Page: 2, PerPage: 50, UseCase: "deck"},
+			want: "page=2&per_page=50&use-case=deck",
+		},
+		{name: "zero paging omitted", opts: SearchOptions{Keywords: "a", Page: 0, PerPage: -1}, want: "keywords=a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.Encode(); got != tt.want {
+				t.Errorf("Encode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchOptionsMatch(t *testing.T) {
+	card := Card{
+		Identifier: "surging-strike-red",
+		Name:       "Surging Strike",
+		Keywords:   []string{"ninja", "action", "attack"},
+		Rarity:     "C",
+		Cost:       NewStat(0),
+		Pitch:      NewStat(1),
+		Printings:  []Printings{{Set: "WTR"}},
+	}
+
+	tests := []struct {
+		name string
+		opts SearchOptions
+		want bool
+	}{
+		{name: "no filters", opts: SearchOptions{}, want: true},
+		{name: "all terms", opts: SearchOptions{Keywords: "Strike NINJA"}, want: true},
+		{name: "missing term", opts: SearchOptions{Keywords: "strike warrior"}, want: false},
+		{name: "class", opts: SearchOptions{Class: "ninja"}, want: true},
+		{name: "other class", opts: SearchOptions{Class: "warrior"}, want: false},
+		{name: "type", opts: SearchOptions{Type: "attack"}, want: true},
+		{name: "set", opts: SearchOptions{Set: "wtr"}, want: true},
+		{name: "other set", opts: SearchOptions{Set: "ARC"}, want: false},
+		{name: "rarity", opts: SearchOptions{Rarity: "c"}, want: true},
+		{name: "pitch", opts: SearchOptions{Pitch: "1"}, want: true},
+		{name: "other pitch", opts: SearchOptions{Pitch: "3"}, want: false},
+		{name: "cost", opts: SearchOptions{Cost: "0"}, want: true},
+		{name: "other cost", opts: SearchOptions{Cost: "1"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.Match(card); got != tt.want {
+				t.Errorf("Match() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientSearchCardsQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageSize int
+		opts     SearchOptions
+		want     string
+	}{
+		{
+			name:     "defaults",
+			pageSize: 30,
+			opts:     SearchOptions{Keywords: "snatch"},
+			want:     "keywords=snatch&page=1&per_page=30&use-case=browse",
+		},
+		{
+			name:     "explicit paging",
+			pageSize: 30,
+			opts:     SearchOptions{Class: "ninja", Page: 3, PerPage: 10},
+			want:     "class=ninja&page=3&per_page=10&use-case=browse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query string
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.RawQuery
+				writeJSON(w, http.StatusOK, `{"data":[{"identifier":"snatch-red"}]}`)
+			})
+			client.pageSize = tt.pageSize
+
+			if _, err := client.SearchCards(context.Background(), tt.opts); err != nil {
+				t.Fatalf("SearchCards() error = %v", err)
+			}
+			if query != tt.want {
+				t.Errorf("SearchCards() sent query %q, want %q", query, tt.want)
+			}
+		})
+	}
+}
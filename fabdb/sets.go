@@ -0,0 +1,110 @@
+package fabdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ErrNoSet is returned by sources without a set of the requested code
+var ErrNoSet = errors.New("no set found")
+
+// Set is a Flesh and Blood card set
+type Set struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Released   string `json:"released"`
+	Browseable bool   `json:"browseable"`
+	Draftable  bool   `json:"draftable"`
+
+	// CardCount is the number of cards in the set, only filled by GetSet
+	CardCount int `json:"-"`
+}
+
+// ListSets returns all sets known to fabdb.net
+func (c *FabDBClient) ListSets(ctx context.Context) ([]Set, error) {
+	resp, err := c.client.get(ctx, "/sets")
+	if err != nil {
+		return nil, err
+	}
+
+	var result setsResponse
+	if err := c.client.decodeJSON(resp, &result); err != nil {
+		return nil, err
+	}
+
+	sets := []Set(result)
+	SortSets(sets)
+	return sets, nil
+}
+
+// GetSet returns the set with the given code, e.g. "WTR", including the number of cards in it
+func (c *FabDBClient) GetSet(ctx context.Context, code string) (Set, error) {
+	resp, err := c.client.get(ctx, "/sets/"+url.PathEscape(strings.ToLower(code)))
+	if err != nil {
+		return Set{}, err
+	}
+
+	var result Set
+	if err := c.client.decodeJSON(resp, &result); err != nil {
+		return Set{}, err
+	}
+
+	page, err := c.searchPage(ctx, SearchOptions{Set: strings.ToUpper(code), PerPage: 1})
+	if err != nil {
+		return Set{}, err
+	}
+	result.CardCount = int(page.Meta.Total)
+
+	return result, nil
+}
+
+// setsResponse decodes a list of sets, either as plain array or wrapped in a data field
+type setsResponse []Set
+
+func (r *setsResponse) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return json.Unmarshal(data, (*[]Set)(r))
+	}
+
+	var wrapped struct {
+		Data []Set `json:"data"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return err
+	}
+	*r = wrapped.Data
+	return nil
+}
+
+// SetsFromPrintings returns all distinct sets of the printings
+func SetsFromPrintings(printings []Printings) []Set {
+	seen := make(map[string]bool)
+	res := []Set{}
+	for _, p := range printings {
+		set := p.Sku.Set
+		if set.ID == "" {
+			set.ID = p.Set
+		}
+		if set.ID == "" || seen[strings.ToUpper(set.ID)] {
+			continue
+		}
+		seen[strings.ToUpper(set.ID)] = true
+		res = append(res, set)
+	}
+	return res
+}
+
+// SortSets sorts sets by release date, newest first
+func SortSets(sets []Set) {
+	sort.SliceStable(sets, func(i, j int) bool {
+		if sets[i].Released != sets[j].Released {
+			return sets[i].Released > sets[j].Released
+		}
+		return sets[i].ID < sets[j].ID
+	})
+}
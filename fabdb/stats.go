@@ -0,0 +1,265 @@
+package fabdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Stat is a numeric card stat. Valid is false if the card does not have the
+// stat at all or if it is variable, like "X" or "*".
+type Stat struct {
+	Value int
+	Valid bool
+}
+
+// NewStat returns a valid Stat of the given value
+func NewStat(v int) Stat {
+	return Stat{Value: v, Valid: true}
+}
+
+// ParseStat parses a stat as printed on a card. Non numeric values result in an invalid Stat.
+func ParseStat(s string) Stat {
+	v, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return Stat{}
+	}
+	return NewStat(v)
+}
+
+func (s Stat) String() string {
+	if !s.Valid {
+		return ""
+	}
+	return strconv.Itoa(s.Value)
+}
+
+// MarshalJSON encodes the stat as number or null if invalid
+func (s Stat) MarshalJSON() ([]byte, error) {
+	if !s.Valid {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.Itoa(s.Value)), nil
+}
+
+// UnmarshalJSON accepts numbers, numeric strings and null
+func (s *Stat) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*s = Stat{}
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+		*s = ParseStat(str)
+		return nil
+	}
+
+	var v int
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("invalid stat %s: %w", data, err)
+	}
+	*s = NewStat(v)
+	return nil
+}
+
+// Pitch values of the three card colors
+const (
+	PitchRed    = 1
+	PitchYellow = 2
+	PitchBlue   = 3
+)
+
+var classes = map[string]bool{
+	"generic":       true,
+	"warrior":       true,
+	"ninja":         true,
+	"guardian":      true,
+	"brute":         true,
+	"wizard":        true,
+	"ranger":        true,
+	"runeblade":     true,
+	"mechanologist": true,
+	"illusionist":   true,
+	"shapeshifter":  true,
+	"merchant":      true,
+	"bard":          true,
+	"assassin":      true,
+	"adjudicator":   true,
+	"necromancer":   true,
+	"thief":         true,
+	"pirate":        true,
+}
+
+var talents = map[string]bool{
+	"light":     true,
+	"shadow":    true,
+	"elemental": true,
+	"earth":     true,
+	"ice":       true,
+	"lightning": true,
+	"draconic":  true,
+	"royal":     true,
+	"mystic":    true,
+	"chaos":     true,
+}
+
+var types = map[string]bool{
+	"action":           true,
+	"instant":          true,
+	"hero":             true,
+	"demi-hero":        true,
+	"equipment":        true,
+	"weapon":           true,
+	"resource":         true,
+	"token":            true,
+	"mentor":           true,
+	"ally":             true,
+	"block":            true,
+	"reaction":         true,
+	"macro":            true,
+	"companion":        true,
+	"landmark":         true,
+	"quest":            true,
+	"attack reaction":  true,
+	"defense reaction": true,
+}
+
+// deriveStats fills the structured stats of the card from the raw stats and
+// keywords of the fabdb payload, unless they have been decoded already.
+func (c *Card) deriveStats() {
+	derive := func(s *Stat, key string) {
+		if !s.Valid {
+			if v, ok := c.Stats[key]; ok {
+				*s = ParseStat(v)
+			}
+		}
+	}
+	derive(&c.Cost, "cost")
+	derive(&c.Pitch, "resource")
+	derive(&c.Power, "attack")
+	derive(&c.Defense, "defense")
+	derive(&c.Life, "life")
+	derive(&c.Intellect, "intellect")
+
+	keywords := make([]string, 0, len(c.Keywords))
+	for _, k := range c.Keywords {
+		keywords = append(keywords, strings.ToLower(k))
+	}
+
+	// class, talent, type and subtypes are derived each on its own, so
+	// that payloads with only some of them still get the others
+	var class, talent, typ string
+	var subtypes []string
+	for i := 0; i < len(keywords); i++ {
+		k := keywords[i]
+		switch {
+		case classes[k] && class == "":
+			class = k
+		case talents[k] && talent == "":
+			talent = k
+		case i+1 < len(keywords) && keywords[i+1] == "reaction" && types[k+" reaction"] && typ == "":
+			typ = k + " reaction"
+			i++
+		case types[k] && typ == "":
+			typ = k
+		default:
+			subtypes = append(subtypes, k)
+		}
+	}
+
+	if c.Class == "" {
+		c.Class = class
+	}
+	if c.Talent == "" {
+		c.Talent = talent
+	}
+	if c.Type == "" {
+		c.Type = typ
+	}
+	if len(c.Subtypes) == 0 {
+		c.Subtypes = subtypes
+	}
+}
+
+// IsPitch reports whether the card pitches for the given value
+func (c Card) IsPitch(pitch int) bool {
+	return c.Pitch.Valid && c.Pitch.Value == pitch
+}
+
+// IsArenaCard reports whether the card starts the game in the arena instead of the deck, like weapons and equipment
+func (c Card) IsArenaCard() bool {
+	return c.Type == "weapon" || c.Type == "equipment"
+}
+
+// HasKeyword reports whether the card has the given keyword
+func (c Card) HasKeyword(keyword string) bool {
+	for _, k := range c.Keywords {
+		if strings.EqualFold(k, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// TypeLine returns the type line of the card, e.g. "Ninja Action - Attack"
+func (c Card) TypeLine() string {
+	var parts []string
+	for _, s := range []string{c.Talent, c.Class, c.Type} {
+		if s != "" {
+			parts = append(parts, Title(s))
+		}
+	}
+
+	line := strings.Join(parts, " ")
+	if len(c.Subtypes) > 0 {
+		line += " - " + Title(strings.Join(c.Subtypes, " "))
+	}
+	return line
+}
+
+// Title returns s with the first letter of every word in upper
+// case, e.g. "Defense Reaction" for "defense reaction". Unlike strings.Title
+// letters after apostrophes are kept as they are.
+func Title(s string) string {
+	runes := []rune(s)
+	start := true
+	for i, r := range runes {
+		if start {
+			runes[i] = unicode.ToUpper(r)
+		}
+		start = unicode.IsSpace(r) || r == '-'
+	}
+	return string(runes)
+}
+
+// StatLine returns a short summary of the card's stats,
+// e.g. "Cost 0 | Pitch 1 | Power 3 | Defense 2"
+func (c Card) StatLine() string {
+	stats := []struct {
+		name string
+		stat Stat
+	}{
+		{"Cost", c.Cost},
+		{"Pitch", c.Pitch},
+		{"Power", c.Power},
+		{"Defense", c.Defense},
+		{"Life", c.Life},
+		{"Intellect", c.Intellect},
+	}
+
+	var parts []string
+	for _, s := range stats {
+		if s.stat.Valid {
+			parts = append(parts, s.name+" "+s.stat.String())
+		}
+	}
+	return strings.Join(parts, " | ")
+}
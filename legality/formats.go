@@ -0,0 +1,78 @@
+package legality
+
+// Format names as used by fabdb.net
+const (
+	FormatBlitz              = "blitz"
+	FormatClassicConstructed = "constructed"
+	FormatCommoner           = "commoner"
+)
+
+// Format are the deck building rules of a format
+type Format struct {
+	// Name is the fabdb.net name of the format, e.g. "blitz"
+	Name string
+	// YoungHero is true if the hero has to be young, false if it has to be adult
+	YoungHero bool
+	// MinDeckSize and MaxDeckSize limit the number of cards in the deck, without weapons and equipment
+	MinDeckSize int
+	MaxDeckSize int
+	// MaxCardPool limits the number of all cards including weapons, equipment and sideboard, 0 if unlimited
+	MaxCardPool int
+	// MaxCopies is the number of copies allowed of every card
+	MaxCopies int
+	// Rarities are the rarities allowed for cards in the deck, all rarities are allowed if empty
+	Rarities []string
+	// ArenaRarities are the rarities allowed for weapons and equipment, all rarities are allowed if empty
+	ArenaRarities []string
+}
+
+// Formats are the supported formats by name
+var Formats = map[string]Format{
+	FormatBlitz: {
+		Name:        FormatBlitz,
+		YoungHero:   true,
+		MinDeckSize: 40,
+		MaxDeckSize: 40,
+		MaxCardPool: 52,
+		MaxCopies:   2,
+	},
+	FormatClassicConstructed: {
+		Name:        FormatClassicConstructed,
+		MinDeckSize: 60,
+		MaxCardPool: 80,
+		MaxCopies:   3,
+	},
+	FormatCommoner: {
+		Name:          FormatCommoner,
+		YoungHero:     true,
+		MinDeckSize:   40,
+		MaxDeckSize:   40,
+		MaxCardPool:   52,
+		MaxCopies:     2,
+		Rarities:      []string{RarityCommon},
+		ArenaRarities: []string{RarityCommon, RarityRare},
+	},
+}
+
+// Rarities as printed on cards
+const (
+	RarityCommon    = "C"
+	RarityRare      = "R"
+	RaritySuperRare = "S"
+	RarityMajestic  = "M"
+	RarityLegendary = "L"
+	RarityFabled    = "F"
+	RarityToken     = "T"
+	RarityPromo     = "P"
+)
+
+var rarityNames = map[string]string{
+	RarityCommon:    "Common",
+	RarityRare:      "Rare",
+	RaritySuperRare: "Super Rare",
+	RarityMajestic:  "Majestic",
+	RarityLegendary: "Legendary",
+	RarityFabled:    "Fabled",
+	RarityToken:     "Token",
+	RarityPromo:     "Promo",
+}
@@ -0,0 +1,271 @@
+// Package legality checks decks against the deck building rules of the
+// constructed formats, like deck size, copy limits and banned cards.
+package legality
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/decklist"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"strings"
+)
+
+// ErrUnknownFormat is returned when checking a deck against an unsupported format
+var ErrUnknownFormat = errors.New("unknown format")
+
+// Rules a deck can violate
+const (
+	RuleHero         = "hero"
+	RuleDeckSize     = "deck_size"
+	RuleCardPool     = "card_pool"
+	RuleCopies       = "copies"
+	RuleClass        = "class"
+	RuleTalent       = "talent"
+	RuleRarity       = "rarity"
+	RuleBanned       = "banned"
+	RuleRestricted   = "restricted"
+	RuleLivingLegend = "living_legend"
+	RuleSuspended    = "suspended"
+)
+
+// Violation is a rule of a format a deck does not follow
+type Violation struct {
+	// Rule is the violated rule, e.g. RuleCopies
+	Rule string
+	// Card is the name of the card violating the rule, empty for rules about the whole deck
+	Card    string
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Message
+}
+
+// Report is the result of checking a deck against a format
+type Report struct {
+	Format     Format
+	Violations []Violation
+	// Listed is false if no list of banned, restricted, living legend and
+	// suspended cards covers the format, so that these were not checked
+	Listed bool
+}
+
+// Legal reports whether the deck follows all rules of the format
+func (r Report) Legal() bool {
+	return len(r.Violations) == 0
+}
+
+// Validator checks decks against formats and a list of banned, restricted,
+// living legend and suspended cards, by default the list shipped with the bot.
+type Validator struct {
+	list List
+}
+
+// ValidatorOption allows for options to be passed into the Validator for customization
+type ValidatorOption func(*Validator)
+
+func NewValidator(opts ...ValidatorOption) *Validator {
+	v := &Validator{list: DefaultList()}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// WithList sets the banned, restricted, living legend and suspended cards checked by the Validator
+func WithList(list List) ValidatorOption {
+	return func(v *Validator) {
+		v.list = list
+	}
+}
+
+// List returns the list of banned, restricted, living legend and suspended cards
+func (v *Validator) List() List {
+	return v.list
+}
+
+// Status returns the status of the card in every format it is listed in
+func (v *Validator) Status(card fabdb.Card) []Status {
+	return v.list.Status(card.Name, card.Identifier)
+}
+
+// LookupFormat returns the format of the given name, e.g. "blitz" or "cc"
+func LookupFormat(name string) (Format, error) {
+	format, ok := Formats[decklist.NormalizeFormat(name)]
+	if !ok {
+		return Format{}, fmt.Errorf("%w %q", ErrUnknownFormat, name)
+	}
+	return format, nil
+}
+
+// Check checks the deck against the rules of the named format
+func (v *Validator) Check(deck fabdb.Deck, format string) (Report, error) {
+	f, err := LookupFormat(format)
+	if err != nil {
+		return Report{}, err
+	}
+	return v.CheckFormat(deck, f), nil
+}
+
+// CheckFormat checks the deck against the rules of the format
+func (v *Validator) CheckFormat(deck fabdb.Deck, format Format) Report {
+	r := Report{Format: format, Listed: v.list.Covers(format.Name)}
+	list := v.list.Formats[format.Name]
+	formatName := decklist.FormatName(format.Name)
+	hero := deck.Hero
+
+	violate := func(rule, card, msg string, args ...interface{}) {
+		r.Violations = append(r.Violations, Violation{Rule: rule, Card: card, Message: fmt.Sprintf(msg, args...)})
+	}
+
+	switch young := hero.HasKeyword("young"); {
+	case hero.Identifier == "":
+		violate(RuleHero, "", "The deck has no hero")
+	case format.YoungHero && !young:
+		violate(RuleHero, hero.Name, "%s is not a young hero, %s requires one", hero.Name, formatName)
+	case !format.YoungHero && young:
+		violate(RuleHero, hero.Name, "%s is a young hero, %s requires an adult hero", hero.Name, formatName)
+	}
+	listedViolation := func(card fabdb.Card) {
+		s, ok := list.status(card.Name, card.Identifier)
+		if !ok {
+			return
+		}
+		switch s.Status {
+		case StatusBanned:
+			violate(RuleBanned, card.Name, "%s is banned in %s", card.Name, formatName)
+		case StatusLivingLegend:
+			violate(RuleLivingLegend, card.Name, "%s is a living legend and no longer legal in %s", card.Name, formatName)
+		case StatusSuspended:
+			violate(RuleSuspended, card.Name, "%s is suspended in %s", card.Name, formatName)
+		}
+	}
+	if hero.Identifier != "" {
+		listedViolation(hero)
+	}
+
+	size := 0
+	for _, c := range deck.Cards {
+		if !c.Card.IsArenaCard() {
+			size += c.Total
+		}
+	}
+	switch {
+	case size < format.MinDeckSize && format.MinDeckSize == format.MaxDeckSize:
+		violate(RuleDeckSize, "", "The deck has %d cards, %s requires exactly %d", size, formatName, format.MinDeckSize)
+	case size < format.MinDeckSize:
+		violate(RuleDeckSize, "", "The deck has %d cards, %s requires at least %d", size, formatName, format.MinDeckSize)
+	case format.MaxDeckSize > 0 && size > format.MaxDeckSize && format.MinDeckSize == format.MaxDeckSize:
+		violate(RuleDeckSize, "", "The deck has %d cards, %s requires exactly %d", size, formatName, format.MaxDeckSize)
+	case format.MaxDeckSize > 0 && size > format.MaxDeckSize:
+		violate(RuleDeckSize, "", "The deck has %d cards, %s allows at most %d", size, formatName, format.MaxDeckSize)
+	}
+
+	if pool := deck.Count() + deck.SideboardCount(); format.MaxCardPool > 0 && pool > format.MaxCardPool {
+		violate(RuleCardPool, "", "The deck has %d cards including equipment, weapons and sideboard, %s allows at most %d", pool, formatName, format.MaxCardPool)
+	}
+
+	for _, c := range uniqueCards(deck) {
+		card := c.Card
+
+		listedViolation(card)
+
+		limit, rule := format.MaxCopies, RuleCopies
+		if card.HasKeyword("legendary") {
+			limit = 1
+		}
+		if n, ok := list.restricted(card.Name, card.Identifier); ok && n < limit {
+			limit, rule = n, RuleRestricted
+		}
+		if c.Total > limit {
+			violate(rule, card.Name, "%dx %s, %s allows %d", c.Total, decklist.CardName(card), formatName, limit)
+		}
+
+		if hero.Identifier != "" {
+			if card.Class != "" && card.Class != "generic" && !hero.HasKeyword(card.Class) {
+				violate(RuleClass, card.Name, "%s is a %s card, %s can't use it", card.Name, fabdb.Title(card.Class), hero.Name)
+			}
+			if card.Talent != "" && !hasTalent(hero, card.Talent) {
+				violate(RuleTalent, card.Name, "%s is a %s card, %s can't use it", card.Name, fabdb.Title(card.Talent), hero.Name)
+			}
+		}
+
+		rarities := format.Rarities
+		if card.IsArenaCard() {
+			rarities = format.ArenaRarities
+		}
+		if len(rarities) > 0 && !hasRarity(card, rarities) {
+			names := make([]string, len(rarities))
+			for i, r := range rarities {
+				names[i] = strings.ToLower(rarityNames[r])
+			}
+			violate(RuleRarity, card.Name, "%s is not printed as %s, which %s requires", decklist.CardName(card), strings.Join(names, " or "), formatName)
+		}
+	}
+
+	return r
+}
+
+// talents are the talents a hero of a talent can use besides its own, e.g.
+// Elemental heroes can use Earth, Ice and Lightning cards
+var talents = map[string][]string{
+	"elemental": {"earth", "ice", "lightning"},
+}
+
+// hasTalent reports whether the hero can use cards of the talent
+func hasTalent(hero fabdb.Card, talent string) bool {
+	if hero.HasKeyword(talent) {
+		return true
+	}
+	for t, included := range talents {
+		if !hero.HasKeyword(t) {
+			continue
+		}
+		for _, i := range included {
+			if strings.EqualFold(i, talent) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// uniqueCards returns the cards of the main deck and sideboard, adding up
+// the copies of cards listed in both.
+func uniqueCards(deck fabdb.Deck) []fabdb.DeckCard {
+	var cards []fabdb.DeckCard
+	index := make(map[string]int)
+	for _, c := range append(append([]fabdb.DeckCard{}, deck.Cards...), deck.Sideboard...) {
+		key := strings.ToLower(c.Card.Name) + "|" + c.Card.Pitch.String()
+		if i, ok := index[key]; ok {
+			cards[i].Total += c.Total
+			continue
+		}
+		index[key] = len(cards)
+		cards = append(cards, c)
+	}
+	return cards
+}
+
+// hasRarity reports whether the card has been printed in any of the rarities
+func hasRarity(card fabdb.Card, rarities []string) bool {
+	printed := []string{card.Rarity}
+	for _, p := range card.Printings {
+		printed = append(printed, p.Rarity)
+	}
+
+	for _, p := range printed {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		// rarities are given as code, like "C", or name, like "Common"
+		code := strings.ToUpper(p[:1])
+		for _, r := range rarities {
+			if code == r {
+				return true
+			}
+		}
+	}
+	return false
+}
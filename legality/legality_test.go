@@ -0,0 +1,256 @@
+package legality
+
+import (
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"reflect"
+	"testing"
+)
+
+func hero(name string, keywords ...string) fabdb.Card {
+	return fabdb.Card{Identifier: name, Name: name, Type: "hero", Keywords: keywords}
+}
+
+func card(name, class, talent, rarity string, keywords ...string) fabdb.Card {
+	return fabdb.Card{
+		Identifier: name,
+		Name:       name,
+		Type:       "action",
+		Class:      class,
+		Talent:     talent,
+		Rarity:     rarity,
+		Keywords:   keywords,
+	}
+}
+
+// filler returns n different generic common cards with the given copies each
+func filler(prefix string, n, copies int) []fabdb.DeckCard {
+	cards := make([]fabdb.DeckCard, n)
+	for i := range cards {
+		cards[i] = fabdb.DeckCard{Card: card(fmt.Sprintf("%s %d", prefix, i), "generic", "", RarityCommon), Total: copies}
+	}
+	return cards
+}
+
+func TestCheck(t *testing.T) {
+	young := hero("Dorinthea", "young", "warrior")
+	adult := hero("Dorinthea Ironsong", "warrior")
+	elemental := hero("Briar", "young", "runeblade", "elemental")
+	ice := hero("Oldhim", "young", "guardian", "ice")
+	helm := fabdb.Card{Identifier: "helm", Name: "Helm", Type: "equipment", Class: "generic", Rarity: RarityRare}
+
+	list := List{Formats: map[string]FormatList{
+		FormatBlitz: {
+			Banned:     []string{"Drone of Brutality"},
+			Restricted: map[string]int{"Bloodrush Bellow": 1},
+		},
+	}}
+
+	tests := []struct {
+		name      string
+		format    string
+		hero      fabdb.Card
+		cards     []fabdb.DeckCard
+		sideboard []fabdb.DeckCard
+		rules     []string
+	}{
+		{
+			name:   "legal blitz deck",
+			format: FormatBlitz,
+			hero:   young,
+			cards:  append(filler("filler", 20, 2), fabdb.DeckCard{Card: helm, Total: 1}),
+		},
+		{
+			name:   "no hero",
+			format: FormatBlitz,
+			cards:  filler("filler", 20, 2),
+			rules:  []string{RuleHero},
+		},
+		{
+			name:   "adult hero in blitz",
+			format: FormatBlitz,
+			hero:   adult,
+			cards:  filler("filler", 20, 2),
+			rules:  []string{RuleHero},
+		},
+		{
+			name:   "young hero in classic constructed",
+			format: FormatClassicConstructed,
+			hero:   young,
+			cards:  filler("filler", 20, 3),
+			rules:  []string{RuleHero},
+		},
+		{
+			name:   "too few cards",
+			format: FormatBlitz,
+			hero:   young,
+			cards:  filler("filler", 19, 2),
+			rules:  []string{RuleDeckSize},
+		},
+		{
+			name:   "too many cards",
+			format: FormatBlitz,
+			hero:   young,
+			cards:  filler("filler", 21, 2),
+			rules:  []string{RuleDeckSize},
+		},
+		{
+			name:      "card pool above 52 in blitz",
+			format:    FormatBlitz,
+			hero:      young,
+			cards:     append(filler("filler", 20, 2), fabdb.DeckCard{Card: helm, Total: 1}),
+			sideboard: []fabdb.DeckCard{{Card: card("side", "generic", "", RarityCommon), Total: 12}},
+			rules:     []string{RuleCardPool, RuleCopies},
+		},
+		{
+			name:      "card pool above 80 in classic constructed",
+			format:    FormatClassicConstructed,
+			hero:      adult,
+			cards:     filler("filler", 20, 3),
+			sideboard: filler("side", 7, 3),
+			rules:     []string{RuleCardPool},
+		},
+		{
+			name:   "too many copies",
+			format: FormatBlitz,
+			hero:   young,
+			cards: append(filler("filler", 18, 2),
+				fabdb.DeckCard{Card: card("triple", "generic", "", RarityCommon), Total: 3},
+				fabdb.DeckCard{Card: card("single", "generic", "", RarityCommon), Total: 1},
+			),
+			rules: []string{RuleCopies},
+		},
+		{
+			name:   "copies in main deck and sideboard add up",
+			format: FormatBlitz,
+			hero:   young,
+			cards:  filler("filler", 20, 2),
+			sideboard: []fabdb.DeckCard{
+				{Card: card("filler 0", "generic", "", RarityCommon), Total: 1},
+			},
+			rules: []string{RuleCopies},
+		},
+		{
+			name:   "legendary card",
+			format: FormatBlitz,
+			hero:   young,
+			cards:  append(filler("filler", 19, 2), fabdb.DeckCard{Card: card("legend", "generic", "", RarityLegendary, "legendary"), Total: 2}),
+			rules:  []string{RuleCopies},
+		},
+		{
+			name:   "card of another class",
+			format: FormatBlitz,
+			hero:   young,
+			cards:  append(filler("filler", 19, 2), fabdb.DeckCard{Card: card("ninja card", "ninja", "", RarityCommon), Total: 2}),
+			rules:  []string{RuleClass},
+		},
+		{
+			name:   "elemental hero uses earth, ice and lightning cards",
+			format: FormatBlitz,
+			hero:   elemental,
+			cards: append(filler("filler", 17, 2),
+				fabdb.DeckCard{Card: card("earth card", "runeblade", "earth", RarityCommon), Total: 2},
+				fabdb.DeckCard{Card: card("ice card", "generic", "ice", RarityCommon), Total: 2},
+				fabdb.DeckCard{Card: card("lightning card", "runeblade", "lightning", RarityCommon), Total: 2},
+			),
+		},
+		{
+			name:   "ice hero can't use lightning cards",
+			format: FormatBlitz,
+			hero:   ice,
+			cards: append(filler("filler", 18, 2),
+				fabdb.DeckCard{Card: card("ice card", "guardian", "ice", RarityCommon), Total: 2},
+				fabdb.DeckCard{Card: card("lightning card", "generic", "lightning", RarityCommon), Total: 2},
+			),
+			rules: []string{RuleTalent},
+		},
+		{
+			name:   "rare card in commoner",
+			format: FormatCommoner,
+			hero:   young,
+			cards: append(filler("filler", 19, 2),
+				fabdb.DeckCard{Card: card("rare card", "generic", "", RarityRare), Total: 2},
+				fabdb.DeckCard{Card: helm, Total: 1},
+			),
+			rules: []string{RuleRarity},
+		},
+		{
+			name:   "banned and restricted cards",
+			format: FormatBlitz,
+			hero:   young,
+			cards: append(filler("filler", 18, 2),
+				fabdb.DeckCard{Card: card("Drone of Brutality", "generic", "", RarityCommon), Total: 2},
+				fabdb.DeckCard{Card: card("Bloodrush Bellow", "generic", "", RarityRare), Total: 2},
+			),
+			rules: []string{RuleBanned, RuleRestricted},
+		},
+	}
+
+	v := NewValidator(WithList(list))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deck := fabdb.Deck{Hero: tt.hero, Cards: tt.cards, Sideboard: tt.sideboard}
+			report, err := v.Check(deck, tt.format)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			var rules []string
+			for _, violation := range report.Violations {
+				rules = append(rules, violation.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.rules) {
+				t.Errorf("Check() violations = %v, want rules %v", report.Violations, tt.rules)
+			}
+			if report.Legal() != (len(tt.rules) == 0) {
+				t.Errorf("Check() legal = %t, want %t", report.Legal(), len(tt.rules) == 0)
+			}
+		})
+	}
+}
+
+func TestCheckUnknownFormat(t *testing.T) {
+	if _, err := NewValidator().Check(fabdb.Deck{}, "draft"); err == nil {
+		t.Error("Check() with unknown format succeeded, want error")
+	}
+}
+
+func TestCheckListed(t *testing.T) {
+	list := List{Formats: map[string]FormatList{FormatBlitz: {}}}
+
+	tests := []struct {
+		name   string
+		v      *Validator
+		format string
+		listed bool
+	}{
+		{name: "default list", v: NewValidator(), format: FormatBlitz, listed: true},
+		{name: "empty list", v: NewValidator(WithList(List{})), format: FormatBlitz, listed: false},
+		{name: "listed format", v: NewValidator(WithList(list)), format: "Blitz", listed: true},
+		{name: "other format", v: NewValidator(WithList(list)), format: "cc", listed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := tt.v.Check(fabdb.Deck{}, tt.format)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if report.Listed != tt.listed {
+				t.Errorf("Check() listed = %t, want %t", report.Listed, tt.listed)
+			}
+		})
+	}
+}
+
+func TestDefaultList(t *testing.T) {
+	list := DefaultList()
+	if list.Version == "" {
+		t.Error("DefaultList() has no version")
+	}
+	for name := range Formats {
+		if !list.Covers(name) {
+			t.Errorf("DefaultList() doesn't cover %s", name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package legality
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Statuses of cards on the list of a format
+const (
+	// StatusBanned cards are not allowed at all
+	StatusBanned = "banned"
+	// StatusRestricted cards are allowed with a limited number of copies only
+	StatusRestricted = "restricted"
+	// StatusLivingLegend cards have retired from the format
+	StatusLivingLegend = "living_legend"
+	// StatusSuspended cards are not allowed until further notice
+	StatusSuspended = "suspended"
+)
+
+// defaultList is the list of banned, restricted, living legend and suspended cards shipped with the bot
+//
+//go:embed banlist.json
+var defaultList []byte
+
+// List are the banned, restricted, living legend and suspended cards of
+// every format. Cards are referenced by name, applying to all pitches, or
+// by fabdb.net identifier.
+//
+//	{
+//	  "version": "2024-01-01",
+//	  "formats": {
+//	    "blitz": {
+//	      "banned": ["Drone of Brutality"],
+//	      "restricted": {"Bloodrush Bellow": 1},
+//	      "suspended": ["Crown of Seeds"]
+//	    },
+//	    "constructed": {
+//	      "living_legend": ["Bravo, Showstopper"]
+//	    }
+//	  }
+//	}
+type List struct {
+	// Version identifies the revision of the list, e.g. the date it has been announced
+	Version string                `json:"version"`
+	Formats map[string]FormatList `json:"formats"`
+}
+
+// FormatList are the banned, restricted, living legend and suspended cards of a format
+type FormatList struct {
+	// Banned cards are not allowed at all
+	Banned []string `json:"banned"`
+	// Restricted cards are allowed with the given number of copies only
+	Restricted map[string]int `json:"restricted"`
+	// LivingLegend cards have retired from the format
+	LivingLegend []string `json:"living_legend"`
+	// Suspended cards are not allowed until further notice
+	Suspended []string `json:"suspended"`
+}
+
+// Status is the status of a card in a format
+type Status struct {
+	// Format is the fabdb.net name of the format, e.g. "blitz"
+	Format string
+	// Status is one of StatusBanned, StatusRestricted, StatusLivingLegend or StatusSuspended
+	Status string
+	// Copies is the number of copies allowed of restricted cards
+	Copies int
+}
+
+// DefaultList returns the list of banned, restricted, living legend and suspended cards shipped with the bot
+func DefaultList() List {
+	list, err := ParseList(defaultList)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default banned and restricted list: %v", err))
+	}
+	return list
+}
+
+// LoadList reads a list of banned, restricted, living legend and suspended cards from a JSON file
+func LoadList(path string) (List, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return List{}, fmt.Errorf("failed to read banned and restricted list: %w", err)
+	}
+
+	list, err := ParseList(data)
+	if err != nil {
+		return List{}, fmt.Errorf("failed to decode banned and restricted list %s: %w", path, err)
+	}
+	return list, nil
+}
+
+// ParseList decodes a list of banned, restricted, living legend and suspended cards
+func ParseList(data []byte) (List, error) {
+	var list List
+	if err := json.Unmarshal(data, &list); err != nil {
+		return List{}, err
+	}
+
+	formats := make(map[string]FormatList, len(list.Formats))
+	for name, l := range list.Formats {
+		formats[strings.ToLower(name)] = l
+	}
+	list.Formats = formats
+	return list, nil
+}
+
+// Covers reports whether the list has an entry for the format
+func (l List) Covers(format string) bool {
+	_, ok := l.Formats[format]
+	return ok
+}
+
+// Status returns the status of the card in every format it is listed in, sorted by format
+func (l List) Status(name, identifier string) []Status {
+	formats := make([]string, 0, len(l.Formats))
+	for format := range l.Formats {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	var res []Status
+	for _, format := range formats {
+		if s, ok := l.Formats[format].status(name, identifier); ok {
+			s.Format = format
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// status returns the status of a card of the given name or identifier
+func (l FormatList) status(name, identifier string) (Status, bool) {
+	switch {
+	case listed(l.Banned, name, identifier):
+		return Status{Status: StatusBanned}, true
+	case listed(l.LivingLegend, name, identifier):
+		return Status{Status: StatusLivingLegend}, true
+	case listed(l.Suspended, name, identifier):
+		return Status{Status: StatusSuspended}, true
+	}
+	if n, ok := l.restricted(name, identifier); ok {
+		return Status{Status: StatusRestricted, Copies: n}, true
+	}
+	return Status{}, false
+}
+
+// restricted returns the number of copies allowed of a restricted card
+func (l FormatList) restricted(name, identifier string) (int, bool) {
+	for r, n := range l.Restricted {
+		if strings.EqualFold(r, name) || strings.EqualFold(r, identifier) {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// listed reports whether a card of the given name or identifier is part of the list
+func listed(list []string, name, identifier string) bool {
+	for _, c := range list {
+		if strings.EqualFold(c, name) || strings.EqualFold(c, identifier) {
+			return true
+		}
+	}
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/pprof"
 	"strings"
+	"time"
 )
 
 const defaultMetricsPath = "/metrics"
@@ -46,6 +47,15 @@ type Metrics interface {
 	IncTelegramCommands(cmd string)
 	IncTelegramEventsIncoming(eventType string)
 	IncTelegramEventsOutgoing(eventType string)
+	IncCardsCacheHits(op string)
+	IncCardsCacheMisses(op string)
+	IncCardsRequestsForwarded(op string)
+	IncCardsRequestsCoalesced(op string)
+	IncFabDBSchemaDrift(resource, field, kind string)
+	ObserveFabDBRequest(endpoint, code string, duration time.Duration)
+	IncFabDBRequestsInFlight(endpoint string)
+	DecFabDBRequestsInFlight(endpoint string)
+	AddFabDBResponseBytes(endpoint string, n int)
 	RegisterHandler(path string, handler *http.ServeMux)
 }
 
@@ -11,11 +11,16 @@ import (
 const (
 	promNamespace         = "fabtcgbot"
 	promTelegramSubsystem = "telegram"
+	promCardsSubsystem    = "cards"
+	promFabDBSubsystem    = "fabdb"
 )
 
 const (
 	TelegramMessageEventType     = "message"
 	TelegramInlineQueryEventType = "inline"
+
+	// TelegramTextCommand is the command label of plain text messages
+	TelegramTextCommand = "text"
 )
 
 // Prometheus implements the prometheus metrics backend.
@@ -23,6 +28,15 @@ type Prometheus struct {
 	telegramCommandsM       *prometheus.CounterVec
 	telegramEventsIncomingM *prometheus.CounterVec
 	telegramEventsOutgoingM *prometheus.CounterVec
+	cardsCacheHitsM         *prometheus.CounterVec
+	cardsCacheMissesM       *prometheus.CounterVec
+	cardsForwardedM         *prometheus.CounterVec
+	cardsCoalescedM         *prometheus.CounterVec
+	fabdbSchemaDriftM       *prometheus.CounterVec
+	fabdbRequestDurationM   *prometheus.HistogramVec
+	fabdbRequestsM          *prometheus.CounterVec
+	fabdbRequestsInFlightM  *prometheus.GaugeVec
+	fabdbResponseBytesM     *prometheus.CounterVec
 	opts                    Options
 	registry                *prometheus.Registry
 	handler                 http.Handler
@@ -60,10 +74,83 @@ func NewPrometheus(opts Options) *Prometheus {
 		Help:      "Total number of outgoing messages.",
 	}, []string{"type"})
 
+	cardsCacheHits := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: promCardsSubsystem,
+		Name:      "cache_hits_total",
+		Help:      "Total number of card lookups served from cache.",
+	}, []string{"operation"})
+
+	cardsCacheMisses := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: promCardsSubsystem,
+		Name:      "cache_misses_total",
+		Help:      "Total number of card lookups not found in cache.",
+	}, []string{"operation"})
+
+	cardsForwarded := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: promCardsSubsystem,
+		Name:      "requests_forwarded_total",
+		Help:      "Total number of card lookups forwarded to the card source.",
+	}, []string{"operation"})
+
+	cardsCoalesced := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: promCardsSubsystem,
+		Name:      "requests_coalesced_total",
+		Help:      "Total number of card lookups sharing the result of an identical in-flight lookup.",
+	}, []string{"operation"})
+
+	fabdbSchemaDrift := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: promFabDBSubsystem,
+		Name:      "schema_drift_total",
+		Help:      "Total number of fabdb response fields not matching the expected schema.",
+	}, []string{"resource", "field", "kind"})
+
+	fabdbRequestDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: promFabDBSubsystem,
+		Name:      "request_duration_seconds",
+		Help:      "Duration of requests to the fabdb API until the response headers are received.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"endpoint"})
+
+	fabdbRequests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: promFabDBSubsystem,
+		Name:      "requests_total",
+		Help:      "Total number of requests to the fabdb API by status code.",
+	}, []string{"endpoint", "code"})
+
+	fabdbRequestsInFlight := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: promFabDBSubsystem,
+		Name:      "requests_in_flight",
+		Help:      "Number of requests to the fabdb API currently in flight.",
+	}, []string{"endpoint"})
+
+	fabdbResponseBytes := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: promFabDBSubsystem,
+		Name:      "response_bytes_total",
+		Help:      "Total number of bytes received from the fabdb API.",
+	}, []string{"endpoint"})
+
 	p := &Prometheus{
 		telegramCommandsM:       telegramCommands,
 		telegramEventsIncomingM: telegramEventsIncoming,
 		telegramEventsOutgoingM: telegramEventsOutgoing,
+		cardsCacheHitsM:         cardsCacheHits,
+		cardsCacheMissesM:       cardsCacheMisses,
+		cardsForwardedM:         cardsForwarded,
+		cardsCoalescedM:         cardsCoalesced,
+		fabdbSchemaDriftM:       fabdbSchemaDrift,
+		fabdbRequestDurationM:   fabdbRequestDuration,
+		fabdbRequestsM:          fabdbRequests,
+		fabdbRequestsInFlightM:  fabdbRequestsInFlight,
+		fabdbResponseBytesM:     fabdbResponseBytes,
 		opts:                    opts,
 		registry:                opts.PrometheusRegistry,
 		handler:                 nil,
@@ -80,6 +167,15 @@ func (p *Prometheus) registerMetrics() {
 	p.registry.MustRegister(p.telegramCommandsM)
 	p.registry.MustRegister(p.telegramEventsIncomingM)
 	p.registry.MustRegister(p.telegramEventsOutgoingM)
+	p.registry.MustRegister(p.cardsCacheHitsM)
+	p.registry.MustRegister(p.cardsCacheMissesM)
+	p.registry.MustRegister(p.cardsForwardedM)
+	p.registry.MustRegister(p.cardsCoalescedM)
+	p.registry.MustRegister(p.fabdbSchemaDriftM)
+	p.registry.MustRegister(p.fabdbRequestDurationM)
+	p.registry.MustRegister(p.fabdbRequestsM)
+	p.registry.MustRegister(p.fabdbRequestsInFlightM)
+	p.registry.MustRegister(p.fabdbResponseBytesM)
 
 	if p.opts.EnableRuntimeMetrics {
 		p.registry.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
@@ -121,3 +217,40 @@ func (p *Prometheus) IncTelegramEventsIncoming(eventType string) {
 func (p *Prometheus) IncTelegramEventsOutgoing(eventType string) {
 	p.telegramEventsOutgoingM.WithLabelValues(eventType).Inc()
 }
+
+func (p *Prometheus) IncCardsCacheHits(op string) {
+	p.cardsCacheHitsM.WithLabelValues(op).Inc()
+}
+
+func (p *Prometheus) IncCardsCacheMisses(op string) {
+	p.cardsCacheMissesM.WithLabelValues(op).Inc()
+}
+
+func (p *Prometheus) IncCardsRequestsForwarded(op string) {
+	p.cardsForwardedM.WithLabelValues(op).Inc()
+}
+
+func (p *Prometheus) IncCardsRequestsCoalesced(op string) {
+	p.cardsCoalescedM.WithLabelValues(op).Inc()
+}
+
+func (p *Prometheus) IncFabDBSchemaDrift(resource, field, kind string) {
+	p.fabdbSchemaDriftM.WithLabelValues(resource, field, kind).Inc()
+}
+
+func (p *Prometheus) ObserveFabDBRequest(endpoint, code string, duration time.Duration) {
+	p.fabdbRequestDurationM.WithLabelValues(endpoint).Observe(duration.Seconds())
+	p.fabdbRequestsM.WithLabelValues(endpoint, code).Inc()
+}
+
+func (p *Prometheus) IncFabDBRequestsInFlight(endpoint string) {
+	p.fabdbRequestsInFlightM.WithLabelValues(endpoint).Inc()
+}
+
+func (p *Prometheus) DecFabDBRequestsInFlight(endpoint string) {
+	p.fabdbRequestsInFlightM.WithLabelValues(endpoint).Dec()
+}
+
+func (p *Prometheus) AddFabDBResponseBytes(endpoint string, n int) {
+	p.fabdbResponseBytesM.WithLabelValues(endpoint).Add(float64(n))
+}
@@ -0,0 +1,257 @@
+package odds
+
+import (
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Turn are the average results of a turn of all goldfish runs
+type Turn struct {
+	Turn int
+	// Pitch is the average pitch value of the cards in hand at the start of the turn
+	Pitch float64
+	// Damage is the average damage dealt with the attacks in hand and the weapons
+	Damage float64
+}
+
+// Goldfish is the result of a goldfish simulation
+type Goldfish struct {
+	Runs  int
+	Turns []Turn
+}
+
+// Hand deals a random hand of intellect cards from the deck, weapons and equipment excluded
+func Hand(cards []fabdb.DeckCard, intellect int, rng *rand.Rand) []fabdb.Card {
+	deck := expand(cards)
+	rng.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+	if intellect > len(deck) {
+		intellect = len(deck)
+	}
+	return deck[:intellect]
+}
+
+// Simulate plays the deck against an opponent that doesn't do anything, a
+// goldfish, for the given number of turns and runs. Every turn, it plays the
+// attacks and weapons dealing the most damage, pitching other cards to pay
+// for them and keeping the rest in hand. Attacks played before another one
+// need go again. Weapons attack once per turn for the resource cost of their
+// attack ability, weapons without such an ability in their text are left
+// out. Pitched cards go to the bottom of the deck, and the hand is refilled
+// to intellect at the end of each turn.
+func Simulate(cards []fabdb.DeckCard, intellect, turns, runs int, rng *rand.Rand) Goldfish {
+	res := Goldfish{Runs: runs, Turns: make([]Turn, turns)}
+	for i := range res.Turns {
+		res.Turns[i].Turn = i + 1
+	}
+	if runs <= 0 {
+		return res
+	}
+	weapons := arenaWeapons(cards)
+
+	for run := 0; run < runs; run++ {
+		deck := expand(cards)
+		rng.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+
+		var hand []fabdb.Card
+		hand, deck = draw(hand, deck, intellect)
+		for turn := 0; turn < turns; turn++ {
+			for _, c := range hand {
+				if c.Pitch.Valid {
+					res.Turns[turn].Pitch += float64(c.Pitch.Value)
+				}
+			}
+
+			damage, kept, pitched := playTurn(hand, weapons)
+			res.Turns[turn].Damage += float64(damage)
+
+			hand = kept
+			deck = append(deck, pitched...)
+			hand, deck = draw(hand, deck, intellect)
+		}
+	}
+
+	for i := range res.Turns {
+		res.Turns[i].Pitch /= float64(runs)
+		res.Turns[i].Damage /= float64(runs)
+	}
+	return res
+}
+
+const (
+	// maxWeapons is the number of weapons a hero can hold, one per hand
+	maxWeapons = 2
+	// maxAttackers limits the attacks considered per turn, as every subset of them is tried
+	maxAttackers = 8
+)
+
+// weapon is a weapon in the arena and the resource cost of its attack
+type weapon struct {
+	card fabdb.Card
+	cost int
+}
+
+// attacker is an attack that can be played in a turn
+type attacker struct {
+	// hand is the index of the card in hand, -1 for weapons
+	hand    int
+	power   int
+	cost    int
+	goAgain bool
+}
+
+// playTurn returns the most damage the hand and weapons can deal in a turn,
+// the cards kept in hand and the cards pitched to pay for the attacks.
+func playTurn(hand []fabdb.Card, weapons []weapon) (damage int, kept, pitched []fabdb.Card) {
+	var attackers []attacker
+	totalPitch := 0
+	for i, c := range hand {
+		if c.Pitch.Valid {
+			totalPitch += c.Pitch.Value
+		}
+		if isAttack(c) {
+			attackers = append(attackers, attacker{hand: i, power: c.Power.Value, cost: c.Cost.Value, goAgain: hasGoAgain(c)})
+		}
+	}
+	for _, w := range weapons {
+		attackers = append(attackers, attacker{hand: -1, power: w.card.Power.Value, cost: w.cost, goAgain: hasGoAgain(w.card)})
+	}
+	if len(attackers) > maxAttackers {
+		sort.SliceStable(attackers, func(i, j int) bool {
+			return attackers[i].power > attackers[j].power
+		})
+		attackers = attackers[:maxAttackers]
+	}
+
+	best, bestMask, bestCost := 0, 0, 0
+	for mask := 1; mask < 1<<len(attackers); mask++ {
+		power, cost, finishers, available := 0, 0, 0, totalPitch
+		for i, a := range attackers {
+			if mask&(1<<i) == 0 {
+				continue
+			}
+			power += a.power
+			cost += a.cost
+			if !a.goAgain {
+				finishers++
+			}
+			if a.hand >= 0 && hand[a.hand].Pitch.Valid {
+				available -= hand[a.hand].Pitch.Value
+			}
+		}
+		if finishers > 1 || cost > available {
+			continue
+		}
+		if power > best || (power == best && cost < bestCost) {
+			best, bestMask, bestCost = power, mask, cost
+		}
+	}
+
+	played := make(map[int]bool)
+	for i, a := range attackers {
+		if bestMask&(1<<i) != 0 && a.hand >= 0 {
+			played[a.hand] = true
+		}
+	}
+
+	// pay for the attacks with as few cards as possible, keeping the others
+	var rest []fabdb.Card
+	for i, c := range hand {
+		if !played[i] {
+			rest = append(rest, c)
+		}
+	}
+	sort.SliceStable(rest, func(i, j int) bool {
+		return rest[i].Pitch.Value > rest[j].Pitch.Value
+	})
+	paid := 0
+	for _, c := range rest {
+		if paid < bestCost && c.Pitch.Valid && c.Pitch.Value > 0 {
+			paid += c.Pitch.Value
+			pitched = append(pitched, c)
+			continue
+		}
+		kept = append(kept, c)
+	}
+
+	return best, kept, pitched
+}
+
+// expand returns the cards shuffled into the deck, one entry per copy
+func expand(cards []fabdb.DeckCard) []fabdb.Card {
+	var deck []fabdb.Card
+	for _, c := range cards {
+		if c.Card.IsArenaCard() {
+			continue
+		}
+		for i := 0; i < c.Total; i++ {
+			deck = append(deck, c.Card)
+		}
+	}
+	return deck
+}
+
+// arenaWeapons returns the weapons the hero attacks with, at most one per
+// hand. Weapons without an attack ability of known cost are left out.
+func arenaWeapons(cards []fabdb.DeckCard) []weapon {
+	var weapons []weapon
+	for _, c := range cards {
+		if c.Card.Type != "weapon" || !c.Card.Power.Valid {
+			continue
+		}
+		cost, ok := WeaponCost(c.Card)
+		if !ok {
+			continue
+		}
+		for i := 0; i < c.Total && len(weapons) < maxWeapons; i++ {
+			weapons = append(weapons, weapon{card: c.Card, cost: cost})
+		}
+	}
+	return weapons
+}
+
+// draw draws cards from the top of the deck until the hand has intellect cards
+func draw(hand, deck []fabdb.Card, intellect int) ([]fabdb.Card, []fabdb.Card) {
+	n := intellect - len(hand)
+	if n > len(deck) {
+		n = len(deck)
+	}
+	if n <= 0 {
+		return hand, deck
+	}
+	hand = append(hand, deck[:n]...)
+	return hand, deck[n:]
+}
+
+// weaponAbilityPattern matches the attack ability of weapons, e.g. "{r}{r}: Attack"
+var weaponAbilityPattern = regexp.MustCompile(`(?i)((?:\{r\}|\[resource\]|\d+)(?:\s*(?:\{r\}|\[resource\]))*)\s*:\s*attack`)
+
+// WeaponCost returns the resource cost of the attack ability of a weapon, as
+// fabdb.net has no cost stat for weapons, e.g. 2 for "{r}{r}: Attack".
+func WeaponCost(card fabdb.Card) (int, bool) {
+	text := strings.ReplaceAll(card.Text, "*", "")
+	m := weaponAbilityPattern.FindStringSubmatch(text)
+	if m == nil {
+		return 0, false
+	}
+
+	ability := strings.ToLower(m[1])
+	cost := strings.Count(ability, "{r}") + strings.Count(ability, "[resource]")
+	if n, err := strconv.Atoi(strings.TrimSpace(ability)); err == nil {
+		cost = n
+	}
+	return cost, true
+}
+
+// isAttack reports whether the card can be played from hand to attack, like attack actions
+func isAttack(card fabdb.Card) bool {
+	return card.Power.Valid && card.Type == "action"
+}
+
+// hasGoAgain reports whether the card has go again printed on it
+func hasGoAgain(card fabdb.Card) bool {
+	return card.HasKeyword("go again") || strings.Contains(strings.ToLower(card.Text), "go again")
+}
@@ -0,0 +1,222 @@
+package odds
+
+import (
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func attack(name string, power, cost, pitch int, goAgain bool) fabdb.Card {
+	card := fabdb.Card{
+		Identifier: name,
+		Name:       name,
+		Type:       "action",
+		Power:      fabdb.NewStat(power),
+		Cost:       fabdb.NewStat(cost),
+		Pitch:      fabdb.NewStat(pitch),
+	}
+	if goAgain {
+		card.Keywords = []string{"go again"}
+	}
+	return card
+}
+
+func instant(name string, pitch int) fabdb.Card {
+	return fabdb.Card{Identifier: name, Name: name, Type: "instant", Pitch: fabdb.NewStat(pitch)}
+}
+
+func sword(text string) fabdb.Card {
+	return fabdb.Card{Identifier: "sword", Name: "Sword", Type: "weapon", Power: fabdb.NewStat(2), Text: text}
+}
+
+func TestWeaponCost(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		cost int
+		ok   bool
+	}{
+		{name: "resource symbols", text: "**Once per Turn Action** - {r}{r}: **Attack**", cost: 2, ok: true},
+		{name: "single resource", text: "Once per Turn Action - {r}: Attack", cost: 1, ok: true},
+		{name: "numeric cost", text: "Once per Turn Action - 0: Attack", cost: 0, ok: true},
+		{name: "resource words", text: "Once per Turn Action - [Resource][Resource][Resource]: Attack", cost: 3, ok: true},
+		{name: "no attack ability", text: "Arcane Barrier 1", ok: false},
+		{name: "no text", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost, ok := WeaponCost(sword(tt.text))
+			if cost != tt.cost || ok != tt.ok {
+				t.Errorf("WeaponCost() = %d, %t, want %d, %t", cost, ok, tt.cost, tt.ok)
+			}
+		})
+	}
+}
+
+func TestPlayTurn(t *testing.T) {
+	many := make([]fabdb.Card, 30)
+	for i := range many {
+		many[i] = attack("flurry", 1, 0, 1, true)
+	}
+
+	tests := []struct {
+		name    string
+		hand    []fabdb.Card
+		weapons []weapon
+		damage  int
+		kept    int
+		pitched int
+	}{
+		{
+			name:    "attack paid by pitching",
+			hand:    []fabdb.Card{attack("strike", 6, 2, 1, false), instant("sink", 3)},
+			damage:  6,
+			pitched: 1,
+		},
+		{
+			name:   "only one attack without go again",
+			hand:   []fabdb.Card{attack("jab", 4, 0, 1, false), attack("strike", 5, 0, 1, false)},
+			damage: 5,
+			kept:   1,
+		},
+		{
+			name:   "go again chains attacks",
+			hand:   []fabdb.Card{attack("jab", 3, 0, 1, true), attack("strike", 5, 0, 1, false)},
+			damage: 8,
+		},
+		{
+			name:    "unaffordable attack is kept",
+			hand:    []fabdb.Card{attack("strike", 7, 3, 1, false), instant("snatch", 1)},
+			damage:  0,
+			kept:    2,
+			pitched: 0,
+		},
+		{
+			name:    "weapon attack costs resources",
+			hand:    []fabdb.Card{instant("sink", 3)},
+			weapons: []weapon{{card: sword(""), cost: 2}},
+			damage:  2,
+			pitched: 1,
+		},
+		{
+			name:    "unaffordable weapon",
+			hand:    []fabdb.Card{instant("snatch", 1)},
+			weapons: []weapon{{card: sword(""), cost: 2}},
+			damage:  0,
+			kept:    1,
+		},
+		{
+			name:   "attacks are limited",
+			hand:   many,
+			damage: maxAttackers,
+			kept:   len(many) - maxAttackers,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			damage, kept, pitched := playTurn(tt.hand, tt.weapons)
+			if damage != tt.damage || len(kept) != tt.kept || len(pitched) != tt.pitched {
+				t.Errorf("playTurn() = %d damage, %d kept, %d pitched, want %d, %d, %d",
+					damage, len(kept), len(pitched), tt.damage, tt.kept, tt.pitched)
+			}
+		})
+	}
+}
+
+func TestHand(t *testing.T) {
+	cards := []fabdb.DeckCard{
+		{Card: sword("{r}: Attack"), Total: 1},
+		{Card: attack("strike", 6, 2, 1, false), Total: 3},
+		{Card: instant("sink", 3), Total: 3},
+	}
+
+	tests := []struct {
+		name      string
+		intellect int
+		size      int
+	}{
+		{name: "full hand", intellect: 4, size: 4},
+		{name: "whole deck", intellect: 10, size: 6},
+		{name: "no intellect", intellect: 0, size: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hand := Hand(cards, tt.intellect, rand.New(rand.NewSource(1)))
+			if len(hand) != tt.size {
+				t.Fatalf("Hand() has %d cards, want %d", len(hand), tt.size)
+			}
+			for _, c := range hand {
+				if c.IsArenaCard() {
+					t.Errorf("Hand() contains arena card %s", c.Name)
+				}
+			}
+			if again := Hand(cards, tt.intellect, rand.New(rand.NewSource(1))); !reflect.DeepEqual(hand, again) {
+				t.Errorf("Hand() with the same seed = %v, want %v", again, hand)
+			}
+		})
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	tests := []struct {
+		name   string
+		cards  []fabdb.DeckCard
+		pitch  float64
+		damage float64
+	}{
+		{
+			name:   "no attacks",
+			cards:  []fabdb.DeckCard{{Card: instant("sink", 3), Total: 40}},
+			pitch:  12,
+			damage: 0,
+		},
+		{
+			name:   "go again attacks",
+			cards:  []fabdb.DeckCard{{Card: attack("flurry", 1, 0, 1, true), Total: 40}},
+			pitch:  4,
+			damage: 4,
+		},
+		{
+			name: "one weapon attack without go again",
+			cards: []fabdb.DeckCard{
+				{Card: sword("{r}{r}: Attack"), Total: 20},
+				{Card: instant("sink", 3), Total: 40},
+			},
+			pitch:  12,
+			damage: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Simulate(tt.cards, 4, 3, 10, rand.New(rand.NewSource(1)))
+			if res.Runs != 10 || len(res.Turns) != 3 {
+				t.Fatalf("Simulate() = %d runs, %d turns, want 10, 3", res.Runs, len(res.Turns))
+			}
+			for _, turn := range res.Turns {
+				if turn.Pitch != tt.pitch || turn.Damage != tt.damage {
+					t.Errorf("turn %d = %.1f pitch, %.1f damage, want %.1f, %.1f", turn.Turn, turn.Pitch, turn.Damage, tt.pitch, tt.damage)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulateSeeded(t *testing.T) {
+	cards := []fabdb.DeckCard{
+		{Card: sword("{r}: Attack"), Total: 1},
+		{Card: attack("strike", 6, 2, 1, false), Total: 12},
+		{Card: attack("jab", 3, 0, 2, true), Total: 12},
+		{Card: instant("sink", 3), Total: 16},
+	}
+
+	a := Simulate(cards, 4, 5, 100, rand.New(rand.NewSource(42)))
+	b := Simulate(cards, 4, 5, 100, rand.New(rand.NewSource(42)))
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Simulate() with the same seed = %+v, want %+v", b, a)
+	}
+}
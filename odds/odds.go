@@ -0,0 +1,91 @@
+// Package odds calculates the probability of drawing cards, following the
+// draw rules of Flesh and Blood: players start with a hand of cards equal to
+// their hero's intellect and draw up to their intellect at the end of each
+// of their turns.
+package odds
+
+import "math"
+
+// Draw is the probability of having drawn the target cards by a turn
+type Draw struct {
+	Turn int
+	// Cards is the number of cards seen by the turn
+	Cards       int
+	Probability float64
+}
+
+// Exactly returns the probability of drawing exactly k of the successes
+// when drawing cards from a deck, following the hypergeometric distribution.
+func Exactly(deck, successes, draws, k int) float64 {
+	if deck <= 0 || successes < 0 || successes > deck || draws < 0 {
+		return 0
+	}
+	if draws > deck {
+		draws = deck
+	}
+	if k < 0 || k > successes || k > draws || draws-k > deck-successes {
+		return 0
+	}
+	return math.Exp(logChoose(successes, k) + logChoose(deck-successes, draws-k) - logChoose(deck, draws))
+}
+
+// AtLeast returns the probability of drawing at least k of the successes
+// when drawing cards from a deck.
+func AtLeast(deck, successes, draws, k int) float64 {
+	if k <= 0 {
+		return 1
+	}
+
+	p := 0.0
+	for i := 0; i < k; i++ {
+		p += Exactly(deck, successes, draws, i)
+	}
+	return math.Max(0, math.Min(1, 1-p))
+}
+
+// CardsSeen returns the number of cards seen by the given turn, starting at
+// 1, for a hero of the given intellect. Kept is the number of cards kept in
+// hand every turn, e.g. to block, which are not drawn again at the end of the
+// turn. With none kept the whole hand is used and drawn again every turn.
+func CardsSeen(intellect, kept, turn int) int {
+	if intellect <= 0 || turn <= 0 {
+		return 0
+	}
+	drawn := intellect - kept
+	if drawn < 0 {
+		drawn = 0
+	}
+	if drawn > intellect {
+		drawn = intellect
+	}
+	return intellect + drawn*(turn-1)
+}
+
+// ByTurn returns the probability of having drawn at least k of the copies
+// of a card for every turn up to the given one, keeping the given number of
+// cards in hand every turn.
+func ByTurn(deck, copies, intellect, kept, turns, k int) []Draw {
+	res := make([]Draw, 0, turns)
+	for turn := 1; turn <= turns; turn++ {
+		cards := CardsSeen(intellect, kept, turn)
+		if cards > deck {
+			cards = deck
+		}
+		res = append(res, Draw{
+			Turn:        turn,
+			Cards:       cards,
+			Probability: AtLeast(deck, copies, cards, k),
+		})
+	}
+	return res
+}
+
+// logChoose returns the natural logarithm of the binomial coefficient n choose k
+func logChoose(n, k int) float64 {
+	return lgamma(n+1) - lgamma(k+1) - lgamma(n-k+1)
+}
+
+func lgamma(n int) float64 {
+	v, _ := math.Lgamma(float64(n))
+	return v
+}
@@ -0,0 +1,105 @@
+package odds
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExactly(t *testing.T) {
+	tests := []struct {
+		name                      string
+		deck, successes, draws, k int
+		want                      float64
+	}{
+		{name: "none of 3 in 40 drawing 4", deck: 40, successes: 3, draws: 4, k: 0, want: 0.7227},
+		{name: "one of 3 in 40 drawing 4", deck: 40, successes: 3, draws: 4, k: 1, want: 0.2551},
+		{name: "all of 3 in 40 drawing 4", deck: 40, successes: 3, draws: 4, k: 3, want: 0.0004},
+		{name: "whole deck drawn", deck: 40, successes: 3, draws: 40, k: 3, want: 1},
+		{name: "draws above deck size", deck: 40, successes: 3, draws: 50, k: 3, want: 1},
+		{name: "more than drawn", deck: 40, successes: 3, draws: 2, k: 3, want: 0},
+		{name: "more than successes", deck: 40, successes: 3, draws: 4, k: 4, want: 0},
+		{name: "successes above deck size", deck: 40, successes: 41, draws: 4, k: 1, want: 0},
+		{name: "empty deck", deck: 0, successes: 0, draws: 4, k: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Exactly(tt.deck, tt.successes, tt.draws, tt.k); math.Abs(got-tt.want) > 1e-4 {
+				t.Errorf("Exactly() = %.4f, want %.4f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	tests := []struct {
+		name                      string
+		deck, successes, draws, k int
+		want                      float64
+	}{
+		{name: "one of 3 in 40 drawing 4", deck: 40, successes: 3, draws: 4, k: 1, want: 0.2773},
+		{name: "two of 3 in 40 drawing 4", deck: 40, successes: 3, draws: 4, k: 2, want: 0.0223},
+		{name: "one of 3 in 60 drawing 4", deck: 60, successes: 3, draws: 4, k: 1, want: 0.1899},
+		{name: "one of 20 in 40 drawing 12", deck: 40, successes: 20, draws: 12, k: 1, want: 0.9999},
+		{name: "none", deck: 40, successes: 3, draws: 4, k: 0, want: 1},
+		{name: "no copies", deck: 40, successes: 0, draws: 4, k: 1, want: 0},
+		{name: "whole deck drawn", deck: 40, successes: 3, draws: 40, k: 3, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AtLeast(tt.deck, tt.successes, tt.draws, tt.k); math.Abs(got-tt.want) > 1e-4 {
+				t.Errorf("AtLeast() = %.4f, want %.4f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCardsSeen(t *testing.T) {
+	tests := []struct {
+		name                  string
+		intellect, kept, turn int
+		want                  int
+	}{
+		{name: "first turn", intellect: 4, turn: 1, want: 4},
+		{name: "full hand used", intellect: 4, turn: 3, want: 12},
+		{name: "one card kept", intellect: 4, kept: 1, turn: 3, want: 10},
+		{name: "whole hand kept", intellect: 4, kept: 4, turn: 3, want: 4},
+		{name: "more kept than intellect", intellect: 4, kept: 5, turn: 3, want: 4},
+		{name: "no intellect", intellect: 0, turn: 3, want: 0},
+		{name: "no turn", intellect: 4, turn: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CardsSeen(tt.intellect, tt.kept, tt.turn); got != tt.want {
+				t.Errorf("CardsSeen() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestByTurn(t *testing.T) {
+	draws := ByTurn(40, 3, 4, 1, 13, 1)
+	if len(draws) != 13 {
+		t.Fatalf("ByTurn() = %d turns, want 13", len(draws))
+	}
+
+	for i, d := range draws {
+		if d.Turn != i+1 {
+			t.Errorf("ByTurn() turn %d = %d", i+1, d.Turn)
+		}
+		if want := CardsSeen(4, 1, d.Turn); d.Cards != want && d.Cards != 40 {
+			t.Errorf("ByTurn() turn %d = %d cards, want %d", d.Turn, d.Cards, want)
+		}
+		if d.Cards > 40 {
+			t.Errorf("ByTurn() turn %d = %d cards, more than the deck", d.Turn, d.Cards)
+		}
+		if i > 0 && d.Probability < draws[i-1].Probability {
+			t.Errorf("ByTurn() turn %d = %.4f, less than the turn before", d.Turn, d.Probability)
+		}
+	}
+	if last := draws[len(draws)-1]; last.Cards != 40 || last.Probability != 1 {
+		t.Errorf("ByTurn() last turn = %d cards, %.4f, want 40, 1", last.Cards, last.Probability)
+	}
+}
@@ -0,0 +1,194 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FormatVersion is the version of the snapshot file format written by this package
+const FormatVersion = 1
+
+const checksumPrefix = "sha256:"
+
+// Snapshot is a full copy of the fabdb.net card pool as stored on disk
+type Snapshot struct {
+	// Version of the snapshot file format
+	Version int `json:"version"`
+	// CreatedAt is the time the snapshot was pulled
+	CreatedAt time.Time `json:"created_at"`
+	// Checksum of the encoded cards and responses, used to detect corrupted
+	// or edited snapshots
+	Checksum string `json:"checksum"`
+	// Cards contains the card pool
+	Cards []fabdb.Card `json:"-"`
+	// Responses are the fabdb.net pages the cards have been pulled from,
+	// revalidated with conditional requests by the next pull
+	Responses []fabdb.CachedResponse `json:"-"`
+}
+
+// file is the on-disk representation of a Snapshot. Cards and responses are
+// kept raw so the checksum can be verified against the exact bytes that were
+// written.
+type file struct {
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"created_at"`
+	Checksum  string          `json:"checksum"`
+	Count     int             `json:"count"`
+	Cards     json.RawMessage `json:"cards"`
+	Responses json.RawMessage `json:"responses,omitempty"`
+}
+
+// New creates a Snapshot of the given cards
+func New(cards []fabdb.Card) *Snapshot {
+	return &Snapshot{
+		Version:   FormatVersion,
+		CreatedAt: time.Now().UTC(),
+		Cards:     cards,
+	}
+}
+
+// Pull fetches the complete card catalogue from fabdb.net. If the client
+// sends conditional requests, the pages of the previous snapshot, if any, are
+// revalidated instead of being downloaded again, and the pulled pages are
+// kept in the snapshot for the next pull.
+func Pull(ctx context.Context, client *fabdb.FabDBClient, previous *Snapshot) (*Snapshot, error) {
+	if previous != nil {
+		client.Client().RestoreCachedResponses(previous.Responses)
+	}
+
+	seen := make(map[string]bool)
+	cards := []fabdb.Card{}
+
+	it := client.IterateCards(ctx, fabdb.SearchOptions{})
+	for it.Next() {
+		for _, card := range it.Cards() {
+			if seen[card.Identifier] {
+				continue
+			}
+			seen[card.Identifier] = true
+			cards = append(cards, card)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("failed to pull cards: %w", err)
+	}
+
+	if len(cards) == 0 {
+		return nil, fmt.Errorf("fabdb did not return any cards")
+	}
+
+	snap := New(cards)
+	snap.Responses = client.Client().CachedResponses()
+	return snap, nil
+}
+
+// Read reads a snapshot file from disk and verifies its version and checksum
+func Read(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot %s: %w", path, err)
+	}
+
+	if f.Version != FormatVersion {
+		return nil, fmt.Errorf("snapshot %s has unsupported version %d, expected %d", path, f.Version, FormatVersion)
+	}
+
+	if sum := checksum(f.Cards, f.Responses); sum != f.Checksum {
+		return nil, fmt.Errorf("snapshot %s checksum mismatch: expected %s, got %s", path, f.Checksum, sum)
+	}
+
+	var cards []fabdb.Card
+	if err := json.Unmarshal(f.Cards, &cards); err != nil {
+		return nil, fmt.Errorf("failed to decode cards of snapshot %s: %w", path, err)
+	}
+
+	if len(cards) == 0 {
+		return nil, fmt.Errorf("snapshot %s does not contain any cards", path)
+	}
+
+	var responses []fabdb.CachedResponse
+	if len(f.Responses) > 0 {
+		if err := json.Unmarshal(f.Responses, &responses); err != nil {
+			return nil, fmt.Errorf("failed to decode responses of snapshot %s: %w", path, err)
+		}
+	}
+
+	return &Snapshot{
+		Version:   f.Version,
+		CreatedAt: f.CreatedAt,
+		Checksum:  f.Checksum,
+		Cards:     cards,
+		Responses: responses,
+	}, nil
+}
+
+// Write encodes the snapshot and atomically replaces the file at path
+func (s *Snapshot) Write(path string) error {
+	cards, err := json.Marshal(s.Cards)
+	if err != nil {
+		return fmt.Errorf("failed to encode cards: %w", err)
+	}
+
+	var responses []byte
+	if len(s.Responses) > 0 {
+		responses, err = json.Marshal(s.Responses)
+		if err != nil {
+			return fmt.Errorf("failed to encode responses: %w", err)
+		}
+	}
+	s.Checksum = checksum(cards, responses)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	err = enc.Encode(file{
+		Version:   s.Version,
+		CreatedAt: s.CreatedAt,
+		Checksum:  s.Checksum,
+		Count:     len(s.Cards),
+		Cards:     cards,
+		Responses: responses,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// checksum hashes the encoded cards followed by the encoded responses, if
+// any, so snapshots without responses keep the checksum of the cards alone.
+func checksum(cards, responses []byte) string {
+	h := sha256.New()
+	h.Write(bytes.TrimSpace(cards))
+	if responses = bytes.TrimSpace(responses); len(responses) > 0 {
+		h.Write([]byte("\n"))
+		h.Write(responses)
+	}
+	return checksumPrefix + hex.EncodeToString(h.Sum(nil))
+}
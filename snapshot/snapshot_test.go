@@ -0,0 +1,208 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteRead(t *testing.T) {
+	snap := New([]fabdb.Card{
+		{Identifier: "snatch-red", Name: "Snatch", Keywords: []string{"generic"}, Pitch: fabdb.NewStat(1)},
+		{Identifier: "dawnblade", Name: "Dawnblade", Extra: map[string]json.RawMessage{"flavour": json.RawMessage(`"Dawn"`)}},
+	})
+	path := filepath.Join(t.TempDir(), "cards.snapshot.json")
+	if err := snap.Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	got, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if got.Version != FormatVersion || got.Checksum != snap.Checksum || !got.CreatedAt.Equal(snap.CreatedAt) {
+		t.Errorf("Read() = version %d, checksum %s, created %s, want %d, %s, %s", got.Version, got.Checksum, got.CreatedAt, FormatVersion, snap.Checksum, snap.CreatedAt)
+	}
+	if len(got.Cards) != 2 || got.Cards[0].Identifier != "snatch-red" || got.Cards[0].Pitch != fabdb.NewStat(1) {
+		t.Errorf("Read() cards = %+v, want %+v", got.Cards, snap.Cards)
+	}
+	if string(got.Cards[1].Extra["flavour"]) != `"Dawn"` {
+		t.Errorf("Read() extra = %s, want %s", got.Cards[1].Extra, snap.Cards[1].Extra)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{name: "not json", data: "cards", err: "failed to decode"},
+		{name: "unsupported version", data: `{"version":2,"cards":[]}`, err: "unsupported version"},
+		{name: "checksum mismatch", data: `{"version":1,"checksum":"sha256:00","cards":[{"identifier":"snatch-red"}]}`, err: "checksum mismatch"},
+		{name: "no cards", data: fmt.Sprintf(`{"version":1,"checksum":%q,"cards":[]}`, checksum([]byte("[]"), nil)), err: "does not contain any cards"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cards.snapshot.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Read(path); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Read() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestPull(t *testing.T) {
+	var requests, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page := r.URL.Query().Get("page")
+		etag := `"page-` + page + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		body := fmt.Sprintf(`{"data":[{"identifier":"snatch-red"},{"identifier":"snatch-blue"}],"links":{"next":"http://%s/cards?page=2"}}`, r.Host)
+		if page == "2" {
+			// the cards of the first page are repeated on the second one
+			body = `{"data":[{"identifier":"snatch-blue"},{"identifier":"dawnblade"}],"links":{"next":null}}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	newClient := func() *fabdb.FabDBClient {
+		return fabdb.NewFabDBClient(
+			fabdb.WithClient(fabdb.NewClient(fabdb.WithAPIEndpoint(srv.URL), fabdb.WithConditionalRequests(10))),
+			fabdb.WithPageSize(2),
+		)
+	}
+
+	tests := []struct {
+		name        string
+		previous    bool
+		notModified int
+	}{
+		{name: "first pull", previous: false, notModified: 0},
+		{name: "revalidated pull", previous: true, notModified: 2},
+	}
+
+	var previous *Snapshot
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests, notModified = 0, 0
+			if !tt.previous {
+				previous = nil
+			}
+
+			snap, err := Pull(context.Background(), newClient(), previous)
+			if err != nil {
+				t.Fatalf("Pull() error = %v", err)
+			}
+
+			var identifiers []string
+			for _, c := range snap.Cards {
+				identifiers = append(identifiers, c.Identifier)
+			}
+			if want := []string{"snatch-red", "snatch-blue", "dawnblade"}; !reflect.DeepEqual(identifiers, want) {
+				t.Errorf("Pull() cards = %v, want %v", identifiers, want)
+			}
+			if requests != 2 || notModified != tt.notModified {
+				t.Errorf("Pull() sent %d requests with %d not modified, want 2 with %d", requests, notModified, tt.notModified)
+			}
+			if len(snap.Responses) != 2 {
+				t.Errorf("Pull() kept %d responses, want 2", len(snap.Responses))
+			}
+			previous = snap
+		})
+	}
+}
+
+func TestReadChecksum(t *testing.T) {
+	snap := New([]fabdb.Card{{Identifier: "snatch-red", Name: "Snatch"}})
+	snap.Responses = []fabdb.CachedResponse{{URL: "https://api.fabdb.net/cards", ETag: `"v1"`, Body: []byte(`{"data":[]}`)}}
+
+	tests := []struct {
+		name  string
+		edit  func([]byte) []byte
+		valid bool
+	}{
+		{
+			name:  "unchanged",
+			edit:  func(b []byte) []byte { return b },
+			valid: true,
+		},
+		{
+			name: "edited card",
+			edit: func(b []byte) []byte { return bytes.Replace(b, []byte(`"Snatch"`), []byte(`"Snitch"`), 1) },
+		},
+		{
+			name: "edited response",
+			edit: func(b []byte) []byte { return bytes.Replace(b, []byte(`\"v1\"`), []byte(`\"v2\"`), 1) },
+		},
+		{
+			name: "removed responses",
+			edit: func(b []byte) []byte {
+				i := bytes.Index(b, []byte(`,"responses"`))
+				return append(b[:i:i], '}', '\n')
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "snapshot.json")
+			if err := snap.Write(path); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, tt.edit(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Read(path)
+			if !tt.valid {
+				if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+					t.Errorf("Read() error = %v, want checksum mismatch", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Responses, snap.Responses) {
+				t.Errorf("Read() responses = %+v, want %+v", got.Responses, snap.Responses)
+			}
+		})
+	}
+}
+
+func TestChecksumWithoutResponses(t *testing.T) {
+	cards := []byte(`[{"identifier":"snatch-red"}]`)
+	if got, want := checksum(cards, nil), checksum(cards, []byte(" ")); got != want {
+		t.Errorf("checksum() = %s, want %s", got, want)
+	}
+	if checksum(cards, nil) == checksum(cards, []byte(`[]`)) {
+		t.Error("checksum() ignores the responses")
+	}
+}
@@ -0,0 +1,126 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"strings"
+)
+
+// maxResults mirrors the page size used by the fabdb.net API client
+const maxResults = 30
+
+// Store is an offline card database backed by a snapshot. It can be used
+// instead of the fabdb.net API wherever a card source is required.
+type Store struct {
+	cards []fabdb.Card
+	index map[string]int
+	sets  map[string]fabdb.Set
+	count map[string]int
+}
+
+// NewStore creates a Store serving the given cards
+func NewStore(cards []fabdb.Card) *Store {
+	s := &Store{
+		cards: cards,
+		index: make(map[string]int, len(cards)),
+		sets:  make(map[string]fabdb.Set),
+		count: make(map[string]int),
+	}
+	for i, card := range cards {
+		s.index[strings.ToLower(card.Identifier)] = i
+		for _, set := range fabdb.SetsFromPrintings(card.Printings) {
+			code := strings.ToUpper(set.ID)
+			if _, ok := s.sets[code]; !ok {
+				s.sets[code] = set
+			}
+			s.count[code]++
+		}
+	}
+	return s
+}
+
+// Load reads a snapshot file from disk and returns a Store serving its cards
+func Load(path string) (*Store, error) {
+	snap, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(snap.Cards), nil
+}
+
+// Len returns the number of cards in the store
+func (s *Store) Len() int {
+	return len(s.cards)
+}
+
+// ListCards returns all cards where every term of the query is found in the
+// card's name, identifier or keywords.
+func (s *Store) ListCards(ctx context.Context, query string) ([]fabdb.Card, error) {
+	return s.SearchCards(ctx, fabdb.SearchOptions{Keywords: query})
+}
+
+// SearchCards returns the requested page of cards matching the search options
+func (s *Store) SearchCards(ctx context.Context, opts fabdb.SearchOptions) ([]fabdb.Card, error) {
+	perPage := opts.PerPage
+	if perPage <= 0 {
+		perPage = maxResults
+	}
+	skip := 0
+	if opts.Page > 1 {
+		skip = (opts.Page - 1) * perPage
+	}
+
+	res := []fabdb.Card{}
+	for _, card := range s.cards {
+		if err := ctx.Err(); err != nil {
+			return []fabdb.Card{}, err
+		}
+		if !opts.Match(card) {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		res = append(res, card)
+		if len(res) >= perPage {
+			break
+		}
+	}
+
+	if len(res) <= 0 {
+		return []fabdb.Card{}, fmt.Errorf("%w in snapshot matching %q", fabdb.ErrNoCards, opts.Encode())
+	}
+	return res, nil
+}
+
+// GetCard returns the card with the given identifier
+func (s *Store) GetCard(ctx context.Context, identifier string) (fabdb.Card, error) {
+	i, ok := s.index[strings.ToLower(identifier)]
+	if !ok {
+		return fabdb.Card{}, fmt.Errorf("%w in snapshot with identifier %q", fabdb.ErrNoCards, identifier)
+	}
+	return s.cards[i], nil
+}
+
+// ListSets returns all sets cards of the snapshot have been printed in
+func (s *Store) ListSets(ctx context.Context) ([]fabdb.Set, error) {
+	sets := make([]fabdb.Set, 0, len(s.sets))
+	for _, set := range s.sets {
+		sets = append(sets, set)
+	}
+	fabdb.SortSets(sets)
+	return sets, nil
+}
+
+// GetSet returns the set with the given code including the number of cards in it
+func (s *Store) GetSet(ctx context.Context, code string) (fabdb.Set, error) {
+	code = strings.ToUpper(code)
+	set, ok := s.sets[code]
+	if !ok {
+		return fabdb.Set{}, fmt.Errorf("%w in snapshot with code %q", fabdb.ErrNoSet, code)
+	}
+	set.CardCount = s.count[code]
+	return set, nil
+}
@@ -0,0 +1,141 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testCards() []fabdb.Card {
+	return []fabdb.Card{
+		{Identifier: "snatch-red", Name: "Snatch", Keywords: []string{"generic", "action", "attack"}, Pitch: fabdb.NewStat(1)},
+		{Identifier: "snatch-blue", Name: "Snatch", Keywords: []string{"generic", "action", "attack"}, Pitch: fabdb.NewStat(3)},
+		{Identifier: "surging-strike-red", Name: "Surging Strike", Keywords: []string{"ninja", "action", "attack"}, Pitch: fabdb.NewStat(1)},
+		{Identifier: "dawnblade", Name: "Dawnblade", Keywords: []string{"warrior", "weapon", "sword"}},
+	}
+}
+
+func TestStoreSearchCards(t *testing.T) {
+	many := make([]fabdb.Card, 40)
+	for i := range many {
+		many[i] = fabdb.Card{Identifier: fmt.Sprintf("card-%d", i), Name: "Card"}
+	}
+
+	tests := []struct {
+		name  string
+		cards []fabdb.Card
+		opts  fabdb.SearchOptions
+		want  []string
+		err   error
+	}{
+		{
+			name:  "keywords",
+			cards: testCards(),
+			opts:  fabdb.SearchOptions{Keywords: "SNATCH"},
+			want:  []string{"snatch-red", "snatch-blue"},
+		},
+		{
+			name:  "every term",
+			cards: testCards(),
+			opts:  fabdb.SearchOptions{Keywords: "strike ninja"},
+			want:  []string{"surging-strike-red"},
+		},
+		{
+			name:  "filters",
+			cards: testCards(),
+			opts:  fabdb.SearchOptions{Type: "attack", Pitch: "1"},
+			want:  []string{"snatch-red", "surging-strike-red"},
+		},
+		{
+			name:  "page",
+			cards: testCards(),
+			opts:  fabdb.SearchOptions{Type: "action", Page: 2, PerPage: 2},
+			want:  []string{"surging-strike-red"},
+		},
+		{
+			name:  "default page size",
+			cards: many,
+			opts:  fabdb.SearchOptions{Keywords: "card"},
+			want:  identifiers(many[:maxResults]),
+		},
+		{
+			name:  "no match",
+			cards: testCards(),
+			opts:  fabdb.SearchOptions{Keywords: "sink below"},
+			err:   fabdb.ErrNoCards,
+		},
+		{
+			name:  "page beyond results",
+			cards: testCards(),
+			opts:  fabdb.SearchOptions{Keywords: "snatch", Page: 2},
+			err:   fabdb.ErrNoCards,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cards, err := NewStore(tt.cards).SearchCards(context.Background(), tt.opts)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("SearchCards() error = %v, want %v", err, tt.err)
+			}
+			if got := identifiers(cards); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SearchCards() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreGetCard(t *testing.T) {
+	tests := []struct {
+		identifier string
+		want       string
+		err        error
+	}{
+		{identifier: "snatch-red", want: "snatch-red"},
+		{identifier: "Snatch-Red", want: "snatch-red"},
+		{identifier: "snatch-yellow", err: fabdb.ErrNoCards},
+	}
+
+	s := NewStore(testCards())
+	for _, tt := range tests {
+		t.Run(tt.identifier, func(t *testing.T) {
+			card, err := s.GetCard(context.Background(), tt.identifier)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("GetCard() error = %v, want %v", err, tt.err)
+			}
+			if card.Identifier != tt.want {
+				t.Errorf("GetCard() = %q, want %q", card.Identifier, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cards.snapshot.json")
+	if err := New(testCards()).Write(path); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if s.Len() != len(testCards()) {
+		t.Errorf("Len() = %d, want %d", s.Len(), len(testCards()))
+	}
+	if _, err := s.GetCard(context.Background(), "dawnblade"); err != nil {
+		t.Errorf("GetCard() error = %v", err)
+	}
+}
+
+func identifiers(cards []fabdb.Card) []string {
+	var res []string
+	for _, c := range cards {
+		res = append(res, c.Identifier)
+	}
+	return res
+}
@@ -0,0 +1,118 @@
+package telegram
+
+import (
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/decklist"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"github.com/cbrgm/fabtcg-bot/legality"
+	"github.com/go-kit/kit/log/level"
+	"gopkg.in/tucnak/telebot.v2"
+	"sort"
+	"strings"
+)
+
+const (
+	responseBanlistUsage = "Please tell me the format, e.g. " + CmdBanlist + " cc. Supported formats are blitz, cc and commoner."
+	responseBanlistEmpty = "✅ There are no banned, restricted, living legend or suspended cards in %s."
+	responseNoBanlist    = "I don't know the banned and restricted cards of %s, no list has been configured for this bot 🤷"
+)
+
+// badgeFormats are the short format names used in badges
+var badgeFormats = map[string]string{
+	legality.FormatBlitz:              "Blitz",
+	legality.FormatClassicConstructed: "CC",
+	legality.FormatCommoner:           "Commoner",
+}
+
+func (b *Bot) handleBanlist(message *telebot.Message) error {
+	level.Info(b.logger).Log(
+		"msg", "user executed banlist command",
+		"username", message.Sender.Username,
+		"user_id", message.Sender.ID,
+		"payload", message.Payload,
+	)
+
+	format, err := legality.LookupFormat(strings.TrimSpace(message.Payload))
+	if err != nil {
+		_, err := b.telegram.Send(message.Chat, responseBanlistUsage)
+		return err
+	}
+
+	list := b.validator.List()
+	if !list.Covers(format.Name) {
+		_, err := b.telegram.Send(message.Chat, fmt.Sprintf(responseNoBanlist, decklist.FormatName(format.Name)))
+		return err
+	}
+	_, err = b.telegram.Send(message.Chat, formatBanlist(format.Name, list.Version, list.Formats[format.Name]))
+	return err
+}
+
+// formatBanlist renders the banned, restricted, living legend and suspended cards of a format
+func formatBanlist(format, version string, list legality.FormatList) string {
+	name := decklist.FormatName(format)
+	restricted := make([]string, 0, len(list.Restricted))
+	for card, n := range list.Restricted {
+		restricted = append(restricted, fmt.Sprintf("%s (%d)", card, n))
+	}
+	sort.Strings(restricted)
+
+	sections := []struct {
+		title string
+		cards []string
+	}{
+		{"🚫 Banned", list.Banned},
+		{"🏛 Living Legend", list.LivingLegend},
+		{"⏸ Suspended", list.Suspended},
+		{"⚠️ Restricted", restricted},
+	}
+
+	var sb strings.Builder
+	for _, s := range sections {
+		if len(s.cards) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s\n", s.title)
+		for _, card := range s.cards {
+			fmt.Fprintf(&sb, "%s\n", card)
+		}
+	}
+	title := fmt.Sprintf("📋 %s banned and restricted list", name)
+	if version != "" {
+		title += fmt.Sprintf(" (%s)", version)
+	}
+	if sb.Len() == 0 {
+		return title + "\n\n" + fmt.Sprintf(responseBanlistEmpty, name)
+	}
+	return title + "\n" + sb.String()
+}
+
+// cardBadges returns the status of a card in all formats, e.g. "🚫 Banned in CC"
+func (b *Bot) cardBadges(card fabdb.Card) string {
+	var badges []string
+	for _, s := range b.validator.Status(card) {
+		format, ok := badgeFormats[s.Format]
+		if !ok {
+			format = decklist.FormatName(s.Format)
+		}
+
+		switch s.Status {
+		case legality.StatusBanned:
+			badges = append(badges, "🚫 Banned in "+format)
+		case legality.StatusLivingLegend:
+			badges = append(badges, "🏛 Living Legend")
+		case legality.StatusSuspended:
+			badges = append(badges, "⏸ Suspended in "+format)
+		case legality.StatusRestricted:
+			badges = append(badges, fmt.Sprintf("⚠️ Restricted to %d in %s", s.Copies, format))
+		}
+	}
+	return strings.Join(badges, " · ")
+}
+
+// badgeSuffix returns the badges of a card to append to its name in card lists, e.g. " · 🚫 Banned in CC"
+func (b *Bot) badgeSuffix(card fabdb.Card) string {
+	if badges := b.cardBadges(card); badges != "" {
+		return " · " + badges
+	}
+	return ""
+}
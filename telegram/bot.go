@@ -2,8 +2,10 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"github.com/cbrgm/fabtcg-bot/legality"
 	"github.com/cbrgm/fabtcg-bot/metrics"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
@@ -23,6 +25,21 @@ const (
 	CmdHelp  = "/help"
 	CmdAbout = "/about"
 
+	// sets
+	CmdSets = "/sets"
+	CmdSet  = "/set"
+
+	// decks
+	CmdDeck   = "/deck"
+	CmdExport = "/export"
+	CmdStats  = "/stats"
+	CmdLegal  = "/legal"
+	CmdOdds   = "/odds"
+	CmdDraw   = "/draw"
+
+	// formats
+	CmdBanlist = "/banlist"
+
 	// debug
 	CmdID = "/id"
 )
@@ -35,11 +52,29 @@ I'm a Flesh and Blood TCG Bot 🤖 on steroids for Telegram. I will send you car
 You can find out more about me using ` + CmdAbout + `
 
 You can share card information from everywhere by simply typing @fabtcg_bot followed by a card query in your chat window.
+
+🔎 Queries can be narrowed down with filters, for example:
+@fabtcg_bot class:ninja pitch:1 cost<=1 type:attack go again
+Supported filters are class, talent, type, set, rarity, pitch, cost, power, defense, life and intellect.
+Numbers can be compared using :, =, !=, <, <=, > and >=.
 	
 👇 Available commands:
 ` + CmdStart + ` - Say hello!
 ` + CmdStop + ` - Say Goodbye!'.
+` + CmdSets + ` - Lists all card sets.
+` + CmdSet + ` <code> [page] - Shows a set and browses its cards, e.g. ` + CmdSet + ` WTR 2.
+` + CmdDeck + ` <link> - Shows a public fabdb.net deck by its link or slug.
+` + CmdExport + ` <format> - Exports the deck loaded in this chat as text, csv, json or fabrary.
+` + CmdStats + ` - Shows pitch, cost curve and type mix of the deck loaded in this chat.
+` + CmdLegal + ` [format] - Checks whether the deck loaded in this chat is legal in blitz, cc or commoner.
+` + CmdOdds + ` <copies|card|category> [deck:40 intellect:4 kept:0 turns:3 atleast:1] - Calculates the odds of drawing cards, e.g. ` + CmdOdds + ` blue.
+` + CmdDraw + ` [goldfish] - Deals an opening hand from the deck loaded in this chat, or simulates turns against a goldfish.
+` + CmdBanlist + ` <format> - Lists the banned, restricted, living legend and suspended cards of a format.
 ` + CmdID + ` - Sends you your Telegram ID (works for all users!).
+
+📝 Paste a decklist into a private chat with me and I'll look up and summarize it, for example:
+Hero: Dorinthea Ironsong
+3x Ironsong Response (Red)
 `
 	responseAbout = `
 This Telegram Bot is a non-commercial hobby project by @cbrgm and is developed as open source software for fans of the FaB TCG!
@@ -52,13 +87,28 @@ This Bot is in no way affiliated with Legend Story Studios®. All intellectual I
 Flesh & Blood™, and set names are trademarks of Legend Story Studios®. Flesh and Blood™ characters, cards, logos, 
 and art are property of Legend Story Studios®.
 `
+	responseNoCards       = "No cards found 🤷"
+	responseNoCardsYet    = "No matches among the next %d cards yet 🔎 Scroll down to search further."
+	responseRateLimited   = "Too many requests right now 🐢 Please try again in a moment."
+	responseUnavailable   = "fabdb.net is currently unavailable 😵 Please try again later."
+	responseInvalidFilter = "Unsupported filter %s 🤔 Class, talent, type, set and rarity only support : and =, stats need a number."
 )
 
 type Cards interface {
 	ListCards(ctx context.Context, query string) ([]fabdb.Card, error)
+	SearchCards(ctx context.Context, opts fabdb.SearchOptions) ([]fabdb.Card, error)
 	GetCard(ctx context.Context, identifier string) (fabdb.Card, error)
 }
 
+type Sets interface {
+	ListSets(ctx context.Context) ([]fabdb.Set, error)
+	GetSet(ctx context.Context, code string) (fabdb.Set, error)
+}
+
+type Decks interface {
+	GetDeck(ctx context.Context, slug string) (fabdb.Deck, error)
+}
+
 type Telebot interface {
 	Start()
 	Stop()
@@ -80,9 +130,14 @@ type Bot struct {
 	startTime time.Time
 	revision  string
 	cards     Cards
+	sets      Sets
+	decks     Decks
 	metrics   BotMetrics
 	telegram  Telebot
 
+	loadedDecks *deckStore
+	validator   *legality.Validator
+
 	allowlist []int
 }
 
@@ -113,7 +168,9 @@ func NewBotWithTelegram(botState Cards, bot Telebot, botMetrics BotMetrics, opts
 		metrics:   botMetrics,
 		telegram:  bot,
 
-		allowlist: []int{},
+		loadedDecks: newDeckStore(),
+		validator:   legality.NewValidator(),
+		allowlist:   []int{},
 	}
 
 	for _, opt := range opts {
@@ -155,6 +212,30 @@ func WithStartTime(t time.Time) BotOption {
 	}
 }
 
+// WithSets sets the source of card sets, enabling the set commands
+func WithSets(s Sets) BotOption {
+	return func(b *Bot) error {
+		b.sets = s
+		return nil
+	}
+}
+
+// WithDecks sets the source of decks, enabling the deck command
+func WithDecks(d Decks) BotOption {
+	return func(b *Bot) error {
+		b.decks = d
+		return nil
+	}
+}
+
+// WithValidator sets the validator checking the legality of decks
+func WithValidator(v *legality.Validator) BotOption {
+	return func(b *Bot) error {
+		b.validator = v
+		return nil
+	}
+}
+
 func WithRevision(s string) BotOption {
 	return func(b *Bot) error {
 		b.revision = s
@@ -180,6 +261,9 @@ func (b *Bot) middleware(next func(*telebot.Message) error) func(*telebot.Messag
 		}
 
 		command := strings.Split(m.Text, " ")[0]
+		if !strings.HasPrefix(command, "/") {
+			command = metrics.TelegramTextCommand
+		}
 		b.metrics.IncTelegramCommands(command)
 
 		level.Debug(b.logger).Log("msg", "received message", "text", m.Text)
@@ -193,6 +277,19 @@ func (b *Bot) middleware(next func(*telebot.Message) error) func(*telebot.Messag
 	}
 }
 
+// privateTextMiddleware passes only text messages of private chats on, like
+// pasted decklists. Group chats and unknown commands are ignored before they
+// are counted as commands.
+func (b *Bot) privateTextMiddleware(next func(*telebot.Message)) func(*telebot.Message) {
+	return func(m *telebot.Message) {
+		if !m.Private() || strings.HasPrefix(m.Text, "/") {
+			b.metrics.IncTelegramEventsIncoming(metrics.TelegramMessageEventType)
+			return
+		}
+		next(m)
+	}
+}
+
 func (b *Bot) queryMiddleware(next func(query *telebot.Query) error) func(query *telebot.Query) {
 	return func(m *telebot.Query) {
 		b.metrics.IncTelegramEventsIncoming(metrics.TelegramInlineQueryEventType)
@@ -214,7 +311,12 @@ func (b *Bot) queryMiddleware(next func(query *telebot.Query) error) func(query
 
 		err := next(m)
 		if err != nil {
-			level.Warn(b.logger).Log("msg", "failed to handle inline query", "err", err)
+			level.Warn(b.logger).Log(
+				"msg", "failed to handle inline query",
+				"from", m.From.ID,
+				"query", m.Text,
+				"err", err,
+			)
 			return
 		}
 
@@ -238,6 +340,16 @@ func (b *Bot) Run(ctx context.Context) error {
 	b.telegram.Handle(CmdStop, b.middleware(b.handleStop))
 	b.telegram.Handle(CmdHelp, b.middleware(b.handleHelp))
 	b.telegram.Handle(CmdAbout, b.middleware(b.handleAbout))
+	b.telegram.Handle(CmdSets, b.middleware(b.handleSets))
+	b.telegram.Handle(CmdSet, b.middleware(b.handleSet))
+	b.telegram.Handle(CmdDeck, b.middleware(b.handleDeck))
+	b.telegram.Handle(CmdExport, b.middleware(b.handleExport))
+	b.telegram.Handle(CmdStats, b.middleware(b.handleStats))
+	b.telegram.Handle(CmdLegal, b.middleware(b.handleLegal))
+	b.telegram.Handle(CmdOdds, b.middleware(b.handleOdds))
+	b.telegram.Handle(CmdDraw, b.middleware(b.handleDraw))
+	b.telegram.Handle(CmdBanlist, b.middleware(b.handleBanlist))
+	b.telegram.Handle(telebot.OnText, b.privateTextMiddleware(b.middleware(b.handleText)))
 	b.telegram.Handle(CmdID, b.middleware(b.handleID))
 
 	// handle inline commands
@@ -298,44 +410,171 @@ func (b *Bot) handleAbout(message *telebot.Message) error {
 }
 
 func (b *Bot) handleOnQuery(q *telebot.Query) error {
-	cards, err := b.cards.ListCards(context.Background(), q.Text)
-	if err != nil {
-		level.Warn(b.logger).Log(
-			"msg", "failed to query cards",
-			"from", q.From.ID,
-			"query", q.Text,
-			"err", err,
-		)
-		return err
+	cards, next, err := b.queryCards(context.Background(), q.Text, q.Offset)
+	if err == nil && len(cards) == 0 && q.Offset != "" && next == "" {
+		// the last page of a paged search, the previous pages were answered already
+		return b.telegram.Answer(q, &telebot.QueryResponse{Results: telebot.Results{}, CacheTime: 60})
+	}
+	if err != nil || len(cards) == 0 {
+		return b.answerQueryError(q, err, next)
 	}
 
 	results := make(telebot.Results, len(cards))
 	for i, card := range cards {
+		description := cardDescription(card)
+		badges := b.cardBadges(card)
+		if badges != "" {
+			description = strings.TrimSpace(badges + "\n" + description)
+		}
+
 		result := &telebot.PhotoResult{
 			URL:         card.Image,
 			Title:       card.Name,
-			Description: card.Text,
+			Description: description,
+			Caption:     badges,
 			ThumbURL:    card.Image,
 		}
 
 		results[i] = result
-		results[i].SetResultID(strconv.Itoa(i))
+		results[i].SetResultID(resultID(q.Offset, i))
 	}
 
 	err = b.telegram.Answer(q, &telebot.QueryResponse{
-		Results:   results,
-		CacheTime: 60,
+		Results:    results,
+		CacheTime:  60,
+		NextOffset: next,
 	})
 	if err != nil {
-		level.Warn(b.logger).Log(
-			"msg", "failed to send query response",
-			"from", q.From.ID,
-			"query", q.Text,
-			"err", err,
-		)
-		return err
+		return fmt.Errorf("failed to send query response: %w", err)
 	}
-	return err
+	return nil
+}
+
+// answerQueryError answers an inline query without results with a single
+// result explaining why, e.g. whether no cards matched or fabdb is down.
+// If the search has more pages, next is the offset to continue with. Errors
+// other than queries without matches are returned to be logged by the
+// queryMiddleware.
+func (b *Bot) answerQueryError(q *telebot.Query, err error, next string) error {
+	var invalidErr invalidFilterError
+
+	title := responseNoCards
+	switch {
+	case err == nil && next != "":
+		title = fmt.Sprintf(responseNoCardsYet, maxQueryPages*maxInlineResults)
+	case err == nil || isNotFound(err):
+	case errors.As(err, &invalidErr):
+		title = fmt.Sprintf(responseInvalidFilter, strings.Join(invalidErr.filters, " "))
+	case isRateLimited(err):
+		title = responseRateLimited
+	default:
+		title = responseUnavailable
+	}
+
+	result := &telebot.ArticleResult{
+		Title:       title,
+		Description: q.Text,
+		Text:        fmt.Sprintf("%s\n\n🔎 %s", title, q.Text),
+	}
+	result.SetResultID(resultID(q.Offset, 0))
+
+	answerErr := b.telegram.Answer(q, &telebot.QueryResponse{
+		Results:    telebot.Results{result},
+		CacheTime:  5,
+		NextOffset: next,
+	})
+	if answerErr != nil {
+		return fmt.Errorf("failed to send query response: %w", answerErr)
+	}
+
+	if err == nil || isNotFound(err) || isInvalidFilter(err) {
+		return nil
+	}
+	return fmt.Errorf("failed to query cards: %w", err)
+}
+
+// resultID returns the ID of the i-th result of an inline query answered at offset
+func resultID(offset string, i int) string {
+	if offset == "" {
+		return strconv.Itoa(i)
+	}
+	return offset + "-" + strconv.Itoa(i)
+}
+
+// isNotFound reports whether the error means that no cards or sets matched a query
+func isNotFound(err error) bool {
+	var notFound fabdb.NotFoundError
+	return errors.Is(err, fabdb.ErrNoCards) || errors.Is(err, fabdb.ErrNoSet) || errors.As(err, &notFound)
+}
+
+// isInvalidFilter reports whether the error was caused by invalid filters in a query
+func isInvalidFilter(err error) bool {
+	var invalidErr invalidFilterError
+	return errors.As(err, &invalidErr)
+}
+
+// isRateLimited reports whether the error was caused by exceeding a rate limit,
+// either on fabdb.net or on the client side.
+func isRateLimited(err error) bool {
+	var (
+		rateErr      fabdb.RateLimitedError
+		throttledErr fabdb.ThrottledError
+	)
+	return errors.As(err, &rateErr) || errors.As(err, &throttledErr)
+}
+
+// cardDescription returns the type line, stats and text of a card
+func cardDescription(card fabdb.Card) string {
+	var lines []string
+	for _, s := range []string{card.TypeLine(), card.StatLine(), card.Text} {
+		if s != "" {
+			lines = append(lines, s)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// queryCards parses an inline query and returns the matching cards. Searches
+// are paged: offset is the first page to search, and the returned offset is
+// the page to continue with or empty if all pages have been searched.
+func (b *Bot) queryCards(ctx context.Context, text, offset string) ([]fabdb.Card, string, error) {
+	query := parseCardQuery(text)
+	if len(query.invalid) > 0 {
+		return nil, "", invalidFilterError{filters: query.invalid}
+	}
+	if query.isPlain() {
+		cards, err := b.cards.ListCards(ctx, text)
+		return cards, "", err
+	}
+
+	opts := query.options
+	opts.PerPage = maxInlineResults
+	opts.Page = 1
+	if page, err := strconv.Atoi(offset); err == nil && page > 1 {
+		opts.Page = page
+	}
+
+	var cards []fabdb.Card
+	for i := 0; i < maxQueryPages; i++ {
+		found, err := b.cards.SearchCards(ctx, opts)
+		if err != nil {
+			if isNotFound(err) && opts.Page > 1 {
+				return cards, "", nil
+			}
+			return nil, "", err
+		}
+
+		// a single page never exceeds the results telegram accepts
+		cards = append(cards, query.filter(found)...)
+		opts.Page++
+		if len(found) < opts.PerPage {
+			return cards, "", nil
+		}
+		if len(cards) > 0 {
+			break
+		}
+	}
+	return cards, strconv.Itoa(opts.Page), nil
 }
 
 func (b *Bot) handleID(message *telebot.Message) error {
@@ -0,0 +1,122 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"gopkg.in/tucnak/telebot.v2"
+	"net/http"
+	"testing"
+)
+
+// fakeTelebot records the answers to inline queries
+type fakeTelebot struct {
+	answers   []*telebot.QueryResponse
+	answerErr error
+}
+
+func (f *fakeTelebot) Start() {}
+func (f *fakeTelebot) Stop()  {}
+func (f *fakeTelebot) Send(telebot.Recipient, interface{}, ...interface{}) (*telebot.Message, error) {
+	return &telebot.Message{}, nil
+}
+func (f *fakeTelebot) Answer(_ *telebot.Query, resp *telebot.QueryResponse) error {
+	f.answers = append(f.answers, resp)
+	return f.answerErr
+}
+func (f *fakeTelebot) Handle(interface{}, interface{}) {}
+
+type nopMetrics struct{}
+
+func (nopMetrics) IncTelegramCommands(string)             {}
+func (nopMetrics) IncTelegramEventsIncoming(string)       {}
+func (nopMetrics) IncTelegramEventsOutgoing(string)       {}
+func (nopMetrics) RegisterHandler(string, *http.ServeMux) {}
+
+// errCards fails every lookup with err
+type errCards struct {
+	err error
+}
+
+func (c errCards) ListCards(context.Context, string) ([]fabdb.Card, error) { return nil, c.err }
+func (c errCards) SearchCards(context.Context, fabdb.SearchOptions) ([]fabdb.Card, error) {
+	return nil, c.err
+}
+func (c errCards) GetCard(context.Context, string) (fabdb.Card, error) { return fabdb.Card{}, c.err }
+
+func TestQueryErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		err       error
+		answerErr error
+		title     string
+		logs      int
+	}{
+		{
+			name:  "no cards",
+			text:  "snatch",
+			err:   fabdb.ErrNoCards,
+			title: responseNoCards,
+		},
+		{
+			name:  "invalid filter",
+			text:  "snatch class!=ninja",
+			err:   fabdb.ErrNoCards,
+			title: fmt.Sprintf(responseInvalidFilter, "class!=ninja"),
+		},
+		{
+			name:  "rate limited",
+			text:  "snatch",
+			err:   fabdb.ThrottledError{},
+			title: responseRateLimited,
+			logs:  1,
+		},
+		{
+			name:  "unavailable",
+			text:  "snatch",
+			err:   fabdb.ServerError{},
+			title: responseUnavailable,
+			logs:  1,
+		},
+		{
+			name:      "answer failed",
+			text:      "snatch",
+			err:       fabdb.ServerError{},
+			answerErr: errors.New("telegram is down"),
+			title:     responseUnavailable,
+			logs:      1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := 0
+			logger := level.NewFilter(log.LoggerFunc(func(keyvals ...interface{}) error {
+				logs++
+				return nil
+			}), level.AllowWarn())
+
+			tb := &fakeTelebot{answerErr: tt.answerErr}
+			b, err := NewBotWithTelegram(errCards{err: tt.err}, tb, nopMetrics{}, WithLogger(logger))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b.queryMiddleware(b.handleOnQuery)(&telebot.Query{Text: tt.text})
+
+			if len(tb.answers) != 1 || len(tb.answers[0].Results) != 1 {
+				t.Fatalf("handleOnQuery() answers = %+v, want a single result", tb.answers)
+			}
+			if got := tb.answers[0].Results[0].(*telebot.ArticleResult).Title; got != tt.title {
+				t.Errorf("handleOnQuery() answered %q, want %q", got, tt.title)
+			}
+			if logs != tt.logs {
+				t.Errorf("handleOnQuery() logged %d times, want %d", logs, tt.logs)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/decklist"
+	"github.com/go-kit/kit/log/level"
+	"gopkg.in/tucnak/telebot.v2"
+	"strings"
+)
+
+const (
+	responseDecklistUsage = "I didn't find a decklist in your message 🤔 Send me one card per line, e.g. 3x Ironsong Response (Red). Check out " + CmdHelp + " for further details."
+	responseDecklistValid = "✅ All cards found."
+	responseUnmatched     = "⚠️ I couldn't match %d lines:"
+)
+
+// handleText validates and summarizes decklists pasted into private chats
+func (b *Bot) handleText(message *telebot.Message) error {
+	level.Info(b.logger).Log(
+		"msg", "user sent decklist",
+		"username", message.Sender.Username,
+		"user_id", message.Sender.ID,
+	)
+
+	list := decklist.Parse(message.Text)
+	if len(list.Lines) == 0 {
+		_, err := b.telegram.Send(message.Chat, responseDecklistUsage)
+		return err
+	}
+	if list.Name == "" {
+		list.Name = "Decklist"
+	}
+
+	result, err := decklist.Resolve(context.Background(), b.cards, list)
+	if err != nil {
+		response := responseUnavailable
+		if isRateLimited(err) {
+			response = responseRateLimited
+		}
+		_, sendErr := b.telegram.Send(message.Chat, response)
+		if sendErr != nil {
+			return sendErr
+		}
+		return err
+	}
+
+	if len(result.Deck.Cards) > 0 {
+		b.loadedDecks.set(message.Chat.ID, result.Deck)
+	}
+
+	_, err = b.telegram.Send(message.Chat, b.formatDecklist(result), telebot.NoPreview)
+	return err
+}
+
+// formatDecklist renders a resolved decklist followed by the lines that couldn't be matched
+func (b *Bot) formatDecklist(result decklist.Result) string {
+	var sb strings.Builder
+	sb.WriteString(b.formatDeck(result.Deck))
+
+	sb.WriteString("\n")
+	if len(result.Unmatched) == 0 {
+		sb.WriteString(responseDecklistValid + "\n")
+	} else {
+		fmt.Fprintf(&sb, responseUnmatched+"\n", len(result.Unmatched))
+		for _, u := range result.Unmatched {
+			fmt.Fprintf(&sb, "%s\n", u)
+		}
+	}
+
+	if len(result.Deck.Cards) > 0 {
+		sb.WriteString("\n" + responseDeckLoaded)
+	}
+	return sb.String()
+}
@@ -0,0 +1,162 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/decklist"
+	"github.com/cbrgm/fabtcg-bot/deckstats"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"github.com/go-kit/kit/log/level"
+	"gopkg.in/tucnak/telebot.v2"
+	"sort"
+	"strings"
+)
+
+const (
+	responseDecksUnavailable = "Sorry, decks are not available right now 😵"
+	responseDeckUsage        = "Please send me a fabdb.net deck link or slug, e.g. " + CmdDeck + " https://fabdb.net/decks/aBcDeFgH"
+	responseDeckNotFound     = "I couldn't find the deck %s 🤷 Only public decks can be shared."
+	responseDeckLoaded       = "💾 The deck is loaded in this chat, try " + CmdStats + ", " + CmdLegal + ", " + CmdDraw + " or " + CmdExport + " text."
+)
+
+// deckGroupOrder is the order in which the card types of a deck are listed
+var deckGroupOrder = []string{
+	"Weapon",
+	"Equipment",
+	"Attack Action",
+	"Action",
+	"Attack Reaction",
+	"Defense Reaction",
+	"Instant",
+	"Resource",
+}
+
+func (b *Bot) handleDeck(message *telebot.Message) error {
+	level.Info(b.logger).Log(
+		"msg", "user executed deck command",
+		"username", message.Sender.Username,
+		"user_id", message.Sender.ID,
+		"payload", message.Payload,
+	)
+
+	if b.decks == nil {
+		_, err := b.telegram.Send(message.Chat, responseDecksUnavailable)
+		return err
+	}
+
+	slug := fabdb.DeckSlug(message.Payload)
+	if slug == "" {
+		_, err := b.telegram.Send(message.Chat, responseDeckUsage)
+		return err
+	}
+
+	deck, err := b.decks.GetDeck(context.Background(), slug)
+	if err != nil {
+		response := responseDecksUnavailable
+		if isNotFound(err) {
+			response = fmt.Sprintf(responseDeckNotFound, slug)
+		}
+		_, sendErr := b.telegram.Send(message.Chat, response, telebot.NoPreview)
+		if sendErr != nil || isNotFound(err) {
+			return sendErr
+		}
+		return err
+	}
+
+	b.loadedDecks.set(message.Chat.ID, deck)
+
+	_, err = b.telegram.Send(message.Chat, b.formatDeck(deck)+"\n\n"+responseDeckLoaded, telebot.NoPreview)
+	return err
+}
+
+// formatDeck renders a deck with its main deck and sideboard grouped by card
+// type, cards listed as banned or restricted are marked with their badges.
+func (b *Bot) formatDeck(deck fabdb.Deck) string {
+	var sb strings.Builder
+	name := deck.Name
+	if name == "" {
+		name = deck.Slug
+	}
+	fmt.Fprintf(&sb, "🃏 %s\n", name)
+	if deck.Hero.Name != "" {
+		fmt.Fprintf(&sb, "🦸 Hero: %s%s\n", deck.Hero.Name, b.badgeSuffix(deck.Hero))
+	}
+	if deck.Format != "" {
+		fmt.Fprintf(&sb, "🏆 Format: %s\n", decklist.FormatName(deck.Format))
+	}
+	fmt.Fprintf(&sb, "%s\n", pitchSummary(deck.Cards))
+
+	fmt.Fprintf(&sb, "\n📜 Main deck (%d cards)\n", deck.Count())
+	b.writeDeckCards(&sb, deck.Cards)
+
+	if len(deck.Sideboard) > 0 {
+		fmt.Fprintf(&sb, "\n🧳 Sideboard (%d cards)\n", deck.SideboardCount())
+		b.writeDeckCards(&sb, deck.Sideboard)
+	}
+
+	if deck.Slug != "" {
+		fmt.Fprintf(&sb, "\n🔗 https://fabdb.net/decks/%s", deck.Slug)
+	}
+	return sb.String()
+}
+
+// writeDeckCards writes the cards grouped by type, each group sorted by pitch and name
+func (b *Bot) writeDeckCards(sb *strings.Builder, cards []fabdb.DeckCard) {
+	groups := make(map[string][]fabdb.DeckCard)
+	for _, c := range cards {
+		group := deckstats.Group(c.Card)
+		groups[group] = append(groups[group], c)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ri, rj := deckGroupRank(names[i]), deckGroupRank(names[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		group := groups[name]
+		sort.SliceStable(group, func(i, j int) bool {
+			pi, pj := group[i].Card.Pitch, group[j].Card.Pitch
+			if pi != pj {
+				return !pi.Valid || (pj.Valid && pi.Value < pj.Value)
+			}
+			return group[i].Card.Name < group[j].Card.Name
+		})
+
+		total := 0
+		for _, c := range group {
+			total += c.Total
+		}
+		fmt.Fprintf(sb, "\n%s (%d)\n", name, total)
+		for _, c := range group {
+			fmt.Fprintf(sb, "%s %dx %s%s\n", pitchSymbol(c.Card), c.Total, c.Card.Name, b.badgeSuffix(c.Card))
+		}
+	}
+}
+
+// pitchSummary returns the number of red, yellow and blue cards, e.g. "🔴 12 🟡 8 🔵 20"
+func pitchSummary(cards []fabdb.DeckCard) string {
+	var counts [4]int
+	for _, c := range cards {
+		if c.Card.Pitch.Valid && c.Card.Pitch.Value >= fabdb.PitchRed && c.Card.Pitch.Value <= fabdb.PitchBlue {
+			counts[c.Card.Pitch.Value] += c.Total
+		}
+	}
+	return fmt.Sprintf("🔴 %d 🟡 %d 🔵 %d", counts[fabdb.PitchRed], counts[fabdb.PitchYellow], counts[fabdb.PitchBlue])
+}
+
+func deckGroupRank(group string) int {
+	for i, g := range deckGroupOrder {
+		if g == group {
+			return i
+		}
+	}
+	return len(deckGroupOrder)
+}
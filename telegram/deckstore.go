@@ -0,0 +1,56 @@
+package telegram
+
+import (
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"sync"
+	"time"
+)
+
+// maxLoadedDecks is the number of chats a loaded deck is kept for
+const maxLoadedDecks = 1000
+
+// deckStore keeps the deck last loaded in every chat, either by link or by
+// pasting a decklist, so that later commands can work with it. If too many
+// chats have loaded a deck, the least recently loaded deck is dropped.
+type deckStore struct {
+	mu    sync.Mutex
+	decks map[int64]loadedDeck
+}
+
+type loadedDeck struct {
+	deck   fabdb.Deck
+	loaded time.Time
+}
+
+func newDeckStore() *deckStore {
+	return &deckStore{decks: make(map[int64]loadedDeck)}
+}
+
+// set stores the deck loaded in the chat, replacing any previous deck
+func (s *deckStore) set(chat int64, deck fabdb.Deck) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.decks[chat]; !ok && len(s.decks) >= maxLoadedDecks {
+		var (
+			oldest     int64
+			oldestTime time.Time
+		)
+		for id, d := range s.decks {
+			if oldestTime.IsZero() || d.loaded.Before(oldestTime) {
+				oldest, oldestTime = id, d.loaded
+			}
+		}
+		delete(s.decks, oldest)
+	}
+	s.decks[chat] = loadedDeck{deck: deck, loaded: time.Now()}
+}
+
+// get returns the deck loaded in the chat
+func (s *deckStore) get(chat int64) (fabdb.Deck, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.decks[chat]
+	return d.deck, ok
+}
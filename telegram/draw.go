@@ -0,0 +1,137 @@
+package telegram
+
+import (
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"github.com/cbrgm/fabtcg-bot/odds"
+	"github.com/go-kit/kit/log/level"
+	"gopkg.in/tucnak/telebot.v2"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultGoldfishTurns and defaultGoldfishRuns are the defaults of a goldfish simulation
+	defaultGoldfishTurns = 5
+	defaultGoldfishRuns  = 1000
+	// maxGoldfishTurns and maxGoldfishRuns limit the effort spent on a goldfish simulation
+	maxGoldfishTurns = 10
+	maxGoldfishRuns  = 10000
+)
+
+const (
+	responseDrawUsage = "Please use " + CmdDraw + " for an opening hand or " + CmdDraw + " goldfish [turns:5 runs:1000] to simulate turns against a goldfish."
+	responseEmptyDeck = "The deck loaded in this chat has no cards to draw 🤷"
+)
+
+func (b *Bot) handleDraw(message *telebot.Message) error {
+	level.Info(b.logger).Log(
+		"msg", "user executed draw command",
+		"username", message.Sender.Username,
+		"user_id", message.Sender.ID,
+		"payload", message.Payload,
+	)
+
+	deck, ok := b.loadedDecks.get(message.Chat.ID)
+	if !ok {
+		_, err := b.telegram.Send(message.Chat, responseNoDeck)
+		return err
+	}
+
+	intellect := defaultIntellect
+	if deck.Hero.Intellect.Valid && deck.Hero.Intellect.Value > 0 {
+		intellect = deck.Hero.Intellect.Value
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	args := strings.Fields(message.Payload)
+	if len(args) == 0 {
+		hand := odds.Hand(deck.Cards, intellect, rng)
+		if len(hand) == 0 {
+			_, err := b.telegram.Send(message.Chat, responseEmptyDeck)
+			return err
+		}
+		_, err := b.telegram.Send(message.Chat, formatHand(hand))
+		return err
+	}
+
+	turns, runs, ok := parseGoldfishArgs(args)
+	if !ok {
+		_, err := b.telegram.Send(message.Chat, responseDrawUsage)
+		return err
+	}
+
+	result := odds.Simulate(deck.Cards, intellect, turns, runs, rng)
+	_, err := b.telegram.Send(message.Chat, formatGoldfish(intellect, result))
+	return err
+}
+
+// parseGoldfishArgs parses the arguments of a goldfish simulation, e.g. "goldfish turns:3"
+func parseGoldfishArgs(args []string) (turns, runs int, ok bool) {
+	if !strings.EqualFold(args[0], "goldfish") {
+		return 0, 0, false
+	}
+
+	turns, runs = defaultGoldfishTurns, defaultGoldfishRuns
+	for _, arg := range args[1:] {
+		key, op, value, ok := splitFilter(arg)
+		if !ok || (op != ":" && op != "=") {
+			return 0, 0, false
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, 0, false
+		}
+		switch key {
+		case "turns", "turn":
+			turns = n
+		case "runs":
+			runs = n
+		default:
+			return 0, 0, false
+		}
+	}
+
+	if turns < 1 || turns > maxGoldfishTurns || runs < 1 || runs > maxGoldfishRuns {
+		return 0, 0, false
+	}
+	return turns, runs, true
+}
+
+// formatHand renders a hand of cards with its total pitch and power
+func formatHand(hand []fabdb.Card) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "🖐 Your opening hand:\n")
+
+	pitch, power := 0, 0
+	for _, card := range hand {
+		fmt.Fprintf(&sb, "%s %s\n", pitchSymbol(card), card.Name)
+		if card.Pitch.Valid {
+			pitch += card.Pitch.Value
+		}
+		if card.Power.Valid {
+			power += card.Power.Value
+		}
+	}
+	fmt.Fprintf(&sb, "\n💎 Pitch: %d | ⚔️ Power: %d", pitch, power)
+	return sb.String()
+}
+
+// formatGoldfish renders the average pitch and damage per turn of a goldfish simulation
+func formatGoldfish(intellect int, result odds.Goldfish) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "🐟 Goldfish over %d turns, %d runs, intellect %d\n\n", len(result.Turns), result.Runs, intellect)
+
+	var damage float64
+	for _, t := range result.Turns {
+		fmt.Fprintf(&sb, "Turn %d: 💎 %.1f pitch | ⚔️ %.1f damage\n", t.Turn, t.Pitch, t.Damage)
+		damage += t.Damage
+	}
+	if len(result.Turns) > 0 {
+		fmt.Fprintf(&sb, "\n⚔️ Average damage per turn: %.1f\n", damage/float64(len(result.Turns)))
+		fmt.Fprintf(&sb, "🩸 Total damage: %.1f", damage)
+	}
+	return sb.String()
+}
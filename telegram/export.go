@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/decklist"
+	"github.com/go-kit/kit/log/level"
+	"gopkg.in/tucnak/telebot.v2"
+	"strings"
+)
+
+const (
+	responseNoDeck       = "There's no deck loaded in this chat yet 🤷 Load one with " + CmdDeck + " <link> or paste a decklist into a private chat with me."
+	responseExportUsage  = "Please tell me the export format, e.g. " + CmdExport + " text. Supported formats are %s."
+	responseExportFailed = "Sorry, I couldn't export the deck 😵"
+)
+
+// exportFiles are the file extensions of exports sent as document instead of text message
+var exportFiles = map[decklist.ExportFormat]string{
+	decklist.ExportCSV:  "csv",
+	decklist.ExportJSON: "json",
+}
+
+func (b *Bot) handleExport(message *telebot.Message) error {
+	level.Info(b.logger).Log(
+		"msg", "user executed export command",
+		"username", message.Sender.Username,
+		"user_id", message.Sender.ID,
+		"payload", message.Payload,
+	)
+
+	deck, ok := b.loadedDecks.get(message.Chat.ID)
+	if !ok {
+		_, err := b.telegram.Send(message.Chat, responseNoDeck)
+		return err
+	}
+
+	format, err := decklist.ParseExportFormat(strings.TrimSpace(message.Payload))
+	if err != nil {
+		formats := make([]string, len(decklist.ExportFormats))
+		for i, f := range decklist.ExportFormats {
+			formats[i] = string(f)
+		}
+		_, err := b.telegram.Send(message.Chat, fmt.Sprintf(responseExportUsage, strings.Join(formats, ", ")))
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := decklist.Export(&buf, deck, format); err != nil {
+		_, sendErr := b.telegram.Send(message.Chat, responseExportFailed)
+		if sendErr != nil {
+			return sendErr
+		}
+		return err
+	}
+
+	if ext, ok := exportFiles[format]; ok {
+		name := deck.Slug
+		if name == "" {
+			name = "deck"
+		}
+		_, err = b.telegram.Send(message.Chat, &telebot.Document{
+			File:     telebot.FromReader(&buf),
+			FileName: name + "." + ext,
+		})
+		return err
+	}
+
+	_, err = b.telegram.Send(message.Chat, buf.String(), telebot.NoPreview)
+	return err
+}
@@ -0,0 +1,68 @@
+package telegram
+
+import (
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/decklist"
+	"github.com/cbrgm/fabtcg-bot/legality"
+	"github.com/go-kit/kit/log/level"
+	"gopkg.in/tucnak/telebot.v2"
+	"strings"
+)
+
+const (
+	responseLegalUsage = "Please tell me the format to check, e.g. " + CmdLegal + " blitz. Supported formats are blitz, cc and commoner."
+	responseLegal      = "✅ %s is legal in %s."
+	responseNotLegal   = "❌ %s is not legal in %s:"
+	responseNotListed  = "⚠️ Banned, restricted, living legend and suspended cards were not checked, no list has been configured for %s."
+)
+
+func (b *Bot) handleLegal(message *telebot.Message) error {
+	level.Info(b.logger).Log(
+		"msg", "user executed legal command",
+		"username", message.Sender.Username,
+		"user_id", message.Sender.ID,
+		"payload", message.Payload,
+	)
+
+	deck, ok := b.loadedDecks.get(message.Chat.ID)
+	if !ok {
+		_, err := b.telegram.Send(message.Chat, responseNoDeck)
+		return err
+	}
+
+	format := strings.TrimSpace(message.Payload)
+	if format == "" {
+		format = deck.Format
+	}
+
+	report, err := b.validator.Check(deck, format)
+	if err != nil {
+		_, err := b.telegram.Send(message.Chat, responseLegalUsage)
+		return err
+	}
+
+	_, err = b.telegram.Send(message.Chat, formatReport(deck.Name, report))
+	return err
+}
+
+// formatReport renders the result of a legality check
+func formatReport(name string, report legality.Report) string {
+	if name == "" {
+		name = "The deck"
+	}
+	formatName := decklist.FormatName(report.Format.Name)
+
+	var sb strings.Builder
+	if report.Legal() {
+		fmt.Fprintf(&sb, responseLegal+"\n", name, formatName)
+	} else {
+		fmt.Fprintf(&sb, responseNotLegal+"\n", name, formatName)
+		for _, v := range report.Violations {
+			fmt.Fprintf(&sb, "• %s\n", v)
+		}
+	}
+	if !report.Listed {
+		fmt.Fprintf(&sb, "\n"+responseNotListed, formatName)
+	}
+	return strings.TrimSpace(sb.String())
+}
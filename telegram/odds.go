@@ -0,0 +1,198 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/decklist"
+	"github.com/cbrgm/fabtcg-bot/deckstats"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"github.com/cbrgm/fabtcg-bot/odds"
+	"github.com/go-kit/kit/log/level"
+	"gopkg.in/tucnak/telebot.v2"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultDeckSize and defaultIntellect are used for odds without loaded deck
+	defaultDeckSize  = 40
+	defaultIntellect = 4
+	// defaultOddsTurns and maxOddsTurns are the number of turns odds are calculated for
+	defaultOddsTurns = 3
+	maxOddsTurns     = 10
+)
+
+const (
+	responseOddsUsage    = "Please tell me what you'd like to draw, e.g. " + CmdOdds + " 3 deck:40 intellect:4 or " + CmdOdds + " blue for the deck loaded in this chat. Options are deck, intellect, kept, turns and atleast, where kept is the number of cards kept in hand every turn."
+	responseOddsNotFound = "I couldn't find %s in the deck loaded in this chat 🤷 Try a card name, a pitch like blue or a type like defense reaction."
+)
+
+var errInvalidOdds = errors.New("invalid odds query")
+
+// oddsQuery is a parsed odds command, e.g. "/odds blue turns:2 atleast:2"
+type oddsQuery struct {
+	// target is a card name or category of the loaded deck, empty if copies is given
+	target    string
+	copies    int
+	deck      int
+	intellect int
+	// kept is the number of cards kept in hand every turn, e.g. to block
+	kept    int
+	turns   int
+	atLeast int
+}
+
+func (b *Bot) handleOdds(message *telebot.Message) error {
+	level.Info(b.logger).Log(
+		"msg", "user executed odds command",
+		"username", message.Sender.Username,
+		"user_id", message.Sender.ID,
+		"payload", message.Payload,
+	)
+
+	query, err := parseOddsQuery(message.Payload)
+	if err != nil {
+		_, err := b.telegram.Send(message.Chat, responseOddsUsage)
+		return err
+	}
+
+	deck, loaded := b.loadedDecks.get(message.Chat.ID)
+	if loaded {
+		stats := deckstats.Analyze(deck.Cards)
+		if query.deck == 0 {
+			query.deck = stats.Cards
+		}
+		if query.intellect == 0 && deck.Hero.Intellect.Valid {
+			query.intellect = deck.Hero.Intellect.Value
+		}
+	}
+
+	if query.target != "" {
+		if !loaded {
+			_, err := b.telegram.Send(message.Chat, responseNoDeck)
+			return err
+		}
+		copies, ok := countTarget(deck.Cards, query.target)
+		if !ok {
+			_, err := b.telegram.Send(message.Chat, fmt.Sprintf(responseOddsNotFound, query.target))
+			return err
+		}
+		query.copies = copies
+	}
+
+	if query.deck == 0 {
+		query.deck = defaultDeckSize
+	}
+	if query.intellect == 0 {
+		query.intellect = defaultIntellect
+	}
+	if query.copies > query.deck || query.kept > query.intellect {
+		_, err := b.telegram.Send(message.Chat, responseOddsUsage)
+		return err
+	}
+
+	_, err = b.telegram.Send(message.Chat, formatOdds(query))
+	return err
+}
+
+// parseOddsQuery parses the target and options of the odds command
+func parseOddsQuery(text string) (oddsQuery, error) {
+	query := oddsQuery{turns: defaultOddsTurns, atLeast: 1}
+
+	var target []string
+	for _, token := range tokenizeQuery(text) {
+		key, op, value, ok := splitFilter(token)
+		if !ok || (op != ":" && op != "=") {
+			target = append(target, token)
+			continue
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return oddsQuery{}, errInvalidOdds
+		}
+		switch key {
+		case "deck":
+			query.deck = n
+		case "intellect", "hand":
+			query.intellect = n
+		case "kept", "keep", "blocked", "block":
+			query.kept = n
+		case "turns", "turn":
+			query.turns = n
+		case "atleast", "min":
+			query.atLeast = n
+		default:
+			return oddsQuery{}, errInvalidOdds
+		}
+	}
+
+	query.target = strings.Join(target, " ")
+	if n, err := strconv.Atoi(query.target); err == nil && n >= 0 {
+		query.target, query.copies = "", n
+	}
+
+	if (query.target == "" && query.copies == 0) || query.turns < 1 || query.turns > maxOddsTurns || query.atLeast < 1 {
+		return oddsQuery{}, errInvalidOdds
+	}
+	return query, nil
+}
+
+// countTarget returns the number of cards of a deck matching a card name or
+// category, like a pitch ("blue"), a type ("defense reaction") or "attack".
+func countTarget(cards []fabdb.DeckCard, target string) (int, bool) {
+	target = strings.ToLower(strings.TrimSpace(target))
+	target = strings.TrimSuffix(target, " pitch")
+
+	pitches := map[string]int{"red": fabdb.PitchRed, "yellow": fabdb.PitchYellow, "blue": fabdb.PitchBlue}
+	matches := func(card fabdb.Card) (bool, bool) {
+		if pitch, ok := pitches[target]; ok {
+			return card.IsPitch(pitch), true
+		}
+		if target == "attack" || target == "attacks" {
+			return card.Power.Valid, true
+		}
+		group := strings.ToLower(deckstats.Group(card))
+		if target == group || target == group+"s" {
+			return true, true
+		}
+		if strings.EqualFold(card.Name, target) || strings.EqualFold(decklist.CardName(card), target) {
+			return true, true
+		}
+		return false, false
+	}
+
+	count, found := 0, false
+	for _, c := range cards {
+		if c.Card.IsArenaCard() {
+			continue
+		}
+		ok, known := matches(c.Card)
+		found = found || known
+		if ok {
+			count += c.Total
+		}
+	}
+	return count, found
+}
+
+// formatOdds renders the probability of drawing the target by turn
+func formatOdds(query oddsQuery) string {
+	var sb strings.Builder
+	what := fmt.Sprintf("%d copies", query.copies)
+	if query.target != "" {
+		what = fmt.Sprintf("%d %s cards", query.copies, query.target)
+	}
+	fmt.Fprintf(&sb, "🎲 Odds of drawing at least %d of %s\n", query.atLeast, what)
+	fmt.Fprintf(&sb, "🃏 Deck: %d cards, intellect %d, ", query.deck, query.intellect)
+	if query.kept == 0 {
+		sb.WriteString("full hand used every turn\n\n")
+	} else {
+		fmt.Fprintf(&sb, "%d cards kept every turn\n\n", query.kept)
+	}
+
+	for _, d := range odds.ByTurn(query.deck, query.copies, query.intellect, query.kept, query.turns, query.atLeast) {
+		fmt.Fprintf(&sb, "Turn %d (%d cards): %.1f%%\n", d.Turn, d.Cards, d.Probability*100)
+	}
+	return sb.String()
+}
@@ -0,0 +1,241 @@
+package telegram
+
+import (
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxInlineResults is the maximum number of results telegram accepts for an inline query
+	maxInlineResults = 50
+	// maxQueryPages is the maximum number of result pages filtered for a single inline query
+	maxQueryPages = 4
+)
+
+// comparison operators supported in card queries, longest first
+var queryOperators = []string{"<=", ">=", "!=", "<", ">", "=", ":"}
+
+// cardQuery is an inline query parsed into fabdb search options and
+// filters that are evaluated against the returned cards.
+//
+// The query syntax consists of free text keywords mixed with filters:
+//
+//	class:ninja pitch:1 cost<=1 type:attack go again
+//
+// Filters on class, talent, type, set and rarity as well as exact matches
+// on pitch and cost are passed on to fabdb, the former only support ":" and
+// "=". Comparisons on pitch, cost, power, defense, life and intellect are
+// applied to the results, page by page. Filters with an unsupported operator
+// or value, like class!=ninja or cost<x, are not searched for as keywords but
+// reported as invalid.
+type cardQuery struct {
+	options fabdb.SearchOptions
+	filters []statFilter
+	invalid []string
+}
+
+// invalidFilterError is returned for queries containing invalid filters
+type invalidFilterError struct {
+	filters []string
+}
+
+func (e invalidFilterError) Error() string {
+	return "invalid query filters: " + strings.Join(e.filters, " ")
+}
+
+// statFilter compares a numeric card stat with a value
+type statFilter struct {
+	stat  string
+	op    string
+	value int
+}
+
+func parseCardQuery(text string) cardQuery {
+	var (
+		q        cardQuery
+		keywords []string
+	)
+
+	for _, token := range tokenizeQuery(text) {
+		key, op, value, ok := splitFilter(token)
+		switch {
+		case !ok || !isFilterKey(key):
+			keywords = append(keywords, token)
+		case value == "":
+			// the filter is still being typed
+		case !q.addFilter(key, op, value):
+			q.invalid = append(q.invalid, token)
+		}
+	}
+
+	q.options.Keywords = strings.Join(keywords, " ")
+	if len(q.filters) > 0 {
+		q.options.PerPage = maxInlineResults
+	}
+	return q
+}
+
+// isFilterKey reports whether key is the name of a query filter
+func isFilterKey(key string) bool {
+	switch key {
+	case "class", "talent", "type", "set", "rarity", "pitch", "cost", "power", "defense", "life", "intellect":
+		return true
+	}
+	return false
+}
+
+// addFilter adds a filter to the query and reports whether the operator and value are supported
+func (q *cardQuery) addFilter(key, op, value string) bool {
+	isEqual := op == ":" || op == "="
+	switch key {
+	case "class", "talent", "type", "set", "rarity":
+		// fabdb only supports exact matches on these
+		if !isEqual {
+			return false
+		}
+	}
+
+	switch key {
+	case "class":
+		q.options.Class = value
+	case "talent":
+		q.options.Talent = value
+	case "type":
+		q.options.Type = value
+	case "set":
+		q.options.Set = strings.ToUpper(value)
+	case "rarity":
+		q.options.Rarity = strings.ToUpper(value)
+	case "pitch", "cost", "power", "defense", "life", "intellect":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return false
+		}
+		switch {
+		case isEqual && key == "pitch":
+			q.options.Pitch = value
+		case isEqual && key == "cost":
+			q.options.Cost = value
+		default:
+			if isEqual {
+				op = "="
+			}
+			q.filters = append(q.filters, statFilter{stat: key, op: op, value: n})
+		}
+		return true
+	default:
+		return false
+	}
+	return true
+}
+
+// isPlain reports whether the query consists of keywords only
+func (q cardQuery) isPlain() bool {
+	return len(q.filters) == 0 && len(q.invalid) == 0 && q.options == fabdb.SearchOptions{Keywords: q.options.Keywords}
+}
+
+// filter returns the cards matching all stat filters of the query
+func (q cardQuery) filter(cards []fabdb.Card) []fabdb.Card {
+	if len(q.filters) == 0 {
+		return cards
+	}
+
+	res := []fabdb.Card{}
+	for _, card := range cards {
+		if q.matches(card) {
+			res = append(res, card)
+		}
+	}
+	return res
+}
+
+func (q cardQuery) matches(card fabdb.Card) bool {
+	for _, f := range q.filters {
+		if !f.matches(card) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f statFilter) matches(card fabdb.Card) bool {
+	v, ok := cardStat(card, f.stat)
+	if !ok {
+		return false
+	}
+
+	switch f.op {
+	case "<":
+		return v < f.value
+	case "<=":
+		return v <= f.value
+	case ">":
+		return v > f.value
+	case ">=":
+		return v >= f.value
+	case "!=":
+		return v != f.value
+	default:
+		return v == f.value
+	}
+}
+
+// cardStat returns the numeric value of a card stat as named in queries
+func cardStat(card fabdb.Card, stat string) (int, bool) {
+	var s fabdb.Stat
+	switch stat {
+	case "pitch":
+		s = card.Pitch
+	case "cost":
+		s = card.Cost
+	case "power":
+		s = card.Power
+	case "defense":
+		s = card.Defense
+	case "life":
+		s = card.Life
+	case "intellect":
+		s = card.Intellect
+	}
+	return s.Value, s.Valid
+}
+
+// splitFilter splits a token like "cost<=1" into key, operator and value
+func splitFilter(token string) (key, op, value string, ok bool) {
+	for i := 1; i < len(token); i++ {
+		for _, o := range queryOperators {
+			if strings.HasPrefix(token[i:], o) {
+				return strings.ToLower(token[:i]), o, token[i+len(o):], true
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+// tokenizeQuery splits a query at whitespace, keeping double quoted values together
+func tokenizeQuery(text string) []string {
+	var (
+		tokens  []string
+		current strings.Builder
+		quoted  bool
+	)
+
+	for _, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
@@ -0,0 +1,169 @@
+package telegram
+
+import (
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeQuery(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "", want: nil},
+		{text: "  snatch   red ", want: []string{"snatch", "red"}},
+		{text: `type:attack "go again"`, want: []string{"type:attack", "go again"}},
+		{text: `class:"mechanologist" cost<=1`, want: []string{"class:mechanologist", "cost<=1"}},
+		{text: "snatch\tred\nblue", want: []string{"snatch", "red", "blue"}},
+		{text: `"unterminated quote`, want: []string{"unterminated quote"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := tokenizeQuery(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenizeQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitFilter(t *testing.T) {
+	tests := []struct {
+		token          string
+		key, op, value string
+		ok             bool
+	}{
+		{token: "class:ninja", key: "class", op: ":", value: "ninja", ok: true},
+		{token: "Cost<=1", key: "cost", op: "<=", value: "1", ok: true},
+		{token: "power>=4", key: "power", op: ">=", value: "4", ok: true},
+		{token: "pitch!=3", key: "pitch", op: "!=", value: "3", ok: true},
+		{token: "defense<3", key: "defense", op: "<", value: "3", ok: true},
+		{token: "life>20", key: "life", op: ">", value: "20", ok: true},
+		{token: "type=attack", key: "type", op: "=", value: "attack", ok: true},
+		{token: "class:", key: "class", op: ":", value: "", ok: true},
+		{token: "snatch", ok: false},
+		{token: ":ninja", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			key, op, value, ok := splitFilter(tt.token)
+			if key != tt.key || op != tt.op || value != tt.value || ok != tt.ok {
+				t.Errorf("splitFilter() = %q, %q, %q, %t, want %q, %q, %q, %t", key, op, value, ok, tt.key, tt.op, tt.value, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseCardQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		query cardQuery
+		plain bool
+	}{
+		{
+			name:  "keywords only",
+			text:  "snatch red",
+			query: cardQuery{options: fabdb.SearchOptions{Keywords: "snatch red"}},
+			plain: true,
+		},
+		{
+			name: "search options",
+			text: "class:ninja talent=light type:attack set:wtr rarity:m pitch:1 cost=0 go again",
+			query: cardQuery{options: fabdb.SearchOptions{
+				Keywords: "go again",
+				Class:    "ninja",
+				Talent:   "light",
+				Type:     "attack",
+				Set:      "WTR",
+				Rarity:   "M",
+				Pitch:    "1",
+				Cost:     "0",
+			}},
+		},
+		{
+			name: "comparisons",
+			text: "cost<=1 power>4 defense=3 pitch!=3",
+			query: cardQuery{
+				options: fabdb.SearchOptions{PerPage: maxInlineResults},
+				filters: []statFilter{
+					{stat: "cost", op: "<=", value: 1},
+					{stat: "power", op: ">", value: 4},
+					{stat: "defense", op: "=", value: 3},
+					{stat: "pitch", op: "!=", value: 3},
+				},
+			},
+		},
+		{
+			name:  "quoted keywords",
+			text:  `"command and conquer" type:action`,
+			query: cardQuery{options: fabdb.SearchOptions{Keywords: "command and conquer", Type: "action"}},
+		},
+		{
+			name:  "unknown keys are keywords",
+			text:  "hero:dorinthea 3:1",
+			query: cardQuery{options: fabdb.SearchOptions{Keywords: "hero:dorinthea 3:1"}},
+			plain: true,
+		},
+		{
+			name: "invalid filters",
+			text: "class!=ninja type<attack cost:x snatch",
+			query: cardQuery{
+				options: fabdb.SearchOptions{Keywords: "snatch"},
+				invalid: []string{"class!=ninja", "type<attack", "cost:x"},
+			},
+		},
+		{
+			name:  "filter without value",
+			text:  "snatch class:",
+			query: cardQuery{options: fabdb.SearchOptions{Keywords: "snatch"}},
+			plain: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := parseCardQuery(tt.text)
+			if !reflect.DeepEqual(q, tt.query) {
+				t.Errorf("parseCardQuery() = %+v, want %+v", q, tt.query)
+			}
+			if q.isPlain() != tt.plain {
+				t.Errorf("isPlain() = %t, want %t", q.isPlain(), tt.plain)
+			}
+		})
+	}
+}
+
+func TestCardQueryFilter(t *testing.T) {
+	cards := []fabdb.Card{
+		{Identifier: "a", Cost: fabdb.NewStat(0), Power: fabdb.NewStat(3), Pitch: fabdb.NewStat(1)},
+		{Identifier: "b", Cost: fabdb.NewStat(2), Power: fabdb.NewStat(5), Pitch: fabdb.NewStat(3)},
+		{Identifier: "c", Cost: fabdb.NewStat(1), Pitch: fabdb.NewStat(2)},
+	}
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "cost<=1", want: []string{"a", "c"}},
+		{text: "cost>=1 power>4", want: []string{"b"}},
+		{text: "power<5", want: []string{"a"}},
+		{text: "pitch!=3", want: []string{"a", "c"}},
+		{text: "power=3", want: []string{"a"}},
+		{text: "power>10", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			var got []string
+			for _, c := range parseCardQuery(tt.text).filter(cards) {
+				got = append(got, c.Identifier)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"github.com/go-kit/kit/log/level"
+	"gopkg.in/tucnak/telebot.v2"
+	"strconv"
+	"strings"
+)
+
+// setPageSize is the number of cards listed per page when browsing a set
+const setPageSize = 30
+
+const (
+	responseSetsUnavailable = "Sorry, card sets are not available right now 😵"
+	responseSetUsage        = "Please tell me which set you'd like to see, e.g. " + CmdSet + " WTR"
+	responseSetNotFound     = "I couldn't find the set %s 🤷 Check out " + CmdSets + " for all sets."
+)
+
+func (b *Bot) handleSets(message *telebot.Message) error {
+	level.Info(b.logger).Log(
+		"msg", "user executed sets command",
+		"username", message.Sender.Username,
+		"user_id", message.Sender.ID,
+	)
+
+	if b.sets == nil {
+		_, err := b.telegram.Send(message.Chat, responseSetsUnavailable)
+		return err
+	}
+
+	sets, err := b.sets.ListSets(context.Background())
+	if err != nil {
+		_, sendErr := b.telegram.Send(message.Chat, responseSetsUnavailable)
+		if sendErr != nil {
+			return sendErr
+		}
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📦 Flesh and Blood sets:\n\n")
+	for _, set := range sets {
+		fmt.Fprintf(&sb, "%s - %s", set.ID, set.Name)
+		if released := releaseDate(set); released != "" {
+			fmt.Fprintf(&sb, " (%s)", released)
+		}
+		if set.Draftable {
+			sb.WriteString(" 🎲")
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "\n🎲 = draftable. Use %s <code> to browse the cards of a set.", CmdSet)
+
+	_, err = b.telegram.Send(message.Chat, sb.String())
+	return err
+}
+
+func (b *Bot) handleSet(message *telebot.Message) error {
+	level.Info(b.logger).Log(
+		"msg", "user executed set command",
+		"username", message.Sender.Username,
+		"user_id", message.Sender.ID,
+		"payload", message.Payload,
+	)
+
+	if b.sets == nil {
+		_, err := b.telegram.Send(message.Chat, responseSetsUnavailable)
+		return err
+	}
+
+	args := strings.Fields(message.Payload)
+	if len(args) == 0 {
+		_, err := b.telegram.Send(message.Chat, responseSetUsage)
+		return err
+	}
+
+	code := strings.ToUpper(args[0])
+	page := 1
+	if len(args) > 1 {
+		if n, err := strconv.Atoi(args[1]); err == nil && n > 0 {
+			page = n
+		}
+	}
+
+	ctx := context.Background()
+	set, err := b.sets.GetSet(ctx, code)
+	if err != nil {
+		response := responseSetsUnavailable
+		if isNotFound(err) {
+			response = fmt.Sprintf(responseSetNotFound, code)
+		}
+		_, sendErr := b.telegram.Send(message.Chat, response)
+		if sendErr != nil || isNotFound(err) {
+			return sendErr
+		}
+		return err
+	}
+
+	cards, err := b.cards.SearchCards(ctx, fabdb.SearchOptions{Set: code, Page: page, PerPage: setPageSize})
+	if err != nil && !isNotFound(err) {
+		_, sendErr := b.telegram.Send(message.Chat, responseSetsUnavailable)
+		if sendErr != nil {
+			return sendErr
+		}
+		return err
+	}
+
+	_, err = b.telegram.Send(message.Chat, formatSet(set, cards, page))
+	return err
+}
+
+// formatSet renders a set and a page of its cards
+func formatSet(set fabdb.Set, cards []fabdb.Card, page int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📦 %s (%s)\n", set.Name, set.ID)
+	if released := releaseDate(set); released != "" {
+		fmt.Fprintf(&sb, "📅 Released: %s\n", released)
+	}
+	fmt.Fprintf(&sb, "🎲 Draftable: %s\n", yesNo(set.Draftable))
+	fmt.Fprintf(&sb, "🃏 Cards: %d\n", set.CardCount)
+
+	pages := (set.CardCount + setPageSize - 1) / setPageSize
+	if len(cards) == 0 {
+		fmt.Fprintf(&sb, "\nThere are no cards on page %d.", page)
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "\nPage %d/%d:\n", page, pages)
+	for _, card := range cards {
+		fmt.Fprintf(&sb, "%s %s\n", pitchSymbol(card), card.Name)
+	}
+	if page < pages {
+		fmt.Fprintf(&sb, "\n➡️ %s %s %d", CmdSet, set.ID, page+1)
+	}
+	return sb.String()
+}
+
+// releaseDate returns the release date of a set without time of day
+func releaseDate(set fabdb.Set) string {
+	if len(set.Released) > 10 {
+		return set.Released[:10]
+	}
+	return set.Released
+}
+
+// pitchSymbol returns a colored symbol for the pitch value of a card
+func pitchSymbol(card fabdb.Card) string {
+	switch {
+	case card.IsPitch(fabdb.PitchRed):
+		return "🔴"
+	case card.IsPitch(fabdb.PitchYellow):
+		return "🟡"
+	case card.IsPitch(fabdb.PitchBlue):
+		return "🔵"
+	default:
+		return "⚪"
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,80 @@
+package telegram
+
+import (
+	"fmt"
+	"github.com/cbrgm/fabtcg-bot/deckstats"
+	"github.com/cbrgm/fabtcg-bot/fabdb"
+	"github.com/go-kit/kit/log/level"
+	"gopkg.in/tucnak/telebot.v2"
+	"strings"
+)
+
+// curveWidth is the maximum length of a bar of the cost curve
+const curveWidth = 10
+
+func (b *Bot) handleStats(message *telebot.Message) error {
+	level.Info(b.logger).Log(
+		"msg", "user executed stats command",
+		"username", message.Sender.Username,
+		"user_id", message.Sender.ID,
+	)
+
+	deck, ok := b.loadedDecks.get(message.Chat.ID)
+	if !ok {
+		_, err := b.telegram.Send(message.Chat, responseNoDeck)
+		return err
+	}
+
+	_, err := b.telegram.Send(message.Chat, formatStats(deck, deckstats.Analyze(deck.Cards)))
+	return err
+}
+
+// formatStats renders a compact report of the stats of a deck
+func formatStats(deck fabdb.Deck, stats deckstats.Stats) string {
+	var sb strings.Builder
+	name := deck.Name
+	if name == "" {
+		name = deck.Slug
+	}
+	fmt.Fprintf(&sb, "📊 %s\n", name)
+	fmt.Fprintf(&sb, "🃏 %d cards, %d weapons and equipment\n", stats.Cards, stats.Arena)
+
+	fmt.Fprintf(&sb, "\n🔴 %d 🟡 %d 🔵 %d", stats.Pitch[fabdb.PitchRed], stats.Pitch[fabdb.PitchYellow], stats.Pitch[fabdb.PitchBlue])
+	if n := stats.Pitch[0]; n > 0 {
+		fmt.Fprintf(&sb, " ⚪ %d", n)
+	}
+	fmt.Fprintf(&sb, "\n💎 Resources: %d (%.2f per card)\n", stats.Resources, stats.AverageResources())
+
+	sb.WriteString("\n💰 Cost curve\n")
+	max := 0
+	for _, n := range stats.Costs {
+		if n > max {
+			max = n
+		}
+	}
+	for cost := 0; cost <= stats.MaxCost() && max > 0; cost++ {
+		n := stats.Costs[cost]
+		fmt.Fprintf(&sb, "%d %s %d\n", cost, strings.Repeat("▇", (n*curveWidth+max-1)/max), n)
+	}
+	if stats.VariableCost > 0 {
+		fmt.Fprintf(&sb, "X %d\n", stats.VariableCost)
+	}
+
+	fmt.Fprintf(&sb, "\n⚔️ Average power: %.2f (%d attacks)\n", stats.AveragePower, stats.Attacks)
+	fmt.Fprintf(&sb, "🛡 Average defense: %.2f\n", stats.AverageDefense)
+
+	sb.WriteString("\n🗂 Types: ")
+	writeCounts(&sb, deckstats.Sorted(stats.Types))
+	sb.WriteString("\n🎓 Classes: ")
+	writeCounts(&sb, deckstats.Sorted(stats.Classes))
+
+	return sb.String()
+}
+
+func writeCounts(sb *strings.Builder, counts []deckstats.Count) {
+	parts := make([]string, len(counts))
+	for i, c := range counts {
+		parts[i] = fmt.Sprintf("%s %d", fabdb.Title(c.Name), c.Count)
+	}
+	sb.WriteString(strings.Join(parts, ", "))
+}